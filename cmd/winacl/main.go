@@ -0,0 +1,337 @@
+// Command winacl decodes Windows security descriptors and prints them as
+// SDDL, JSON, a human-readable table or English sentences.
+//
+// Usage:
+//
+//	winacl [-in auto|base64|hex|raw] [-out sddl|json|table|explain] [-schema schema.ldif] [-batch] [file ...]
+//
+// Descriptors are read from the named files, or from stdin when no file
+// (or "-") is given. In batch mode, every non-empty line of the input is
+// decoded as a separate base64 or hex encoded descriptor, which makes the
+// tool usable at the end of a shell pipeline:
+//
+//	ldapsearch ... | grep nTSecurityDescriptor:: | cut -d' ' -f2 | winacl -batch -out json
+//
+// The schema given by -schema resolves GUIDs in table and explain output
+// only; JSON output names GUIDs from the built-in tables
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+)
+
+// Input encodings accepted by -in
+const (
+	inputAuto   = "auto"
+	inputBase64 = "base64"
+	inputHex    = "hex"
+	inputRaw    = "raw"
+)
+
+// Output formats accepted by -out
+const (
+	outputSDDL    = "sddl"
+	outputJSON    = "json"
+	outputTable   = "table"
+	outputExplain = "explain"
+)
+
+type options struct {
+	input    string
+	output   string
+	batch    bool
+	resolver *winacl.GUIDResolver
+}
+
+func main() {
+	opts := options{}
+	schemaPath := ""
+
+	flag.StringVar(&opts.input, "in", inputAuto, "input encoding: auto, base64, hex or raw")
+	flag.StringVar(&opts.output, "out", outputSDDL, "output format: sddl, json, table or explain")
+	flag.BoolVar(&opts.batch, "batch", false, "decode every input line as a separate base64 or hex descriptor")
+	flag.StringVar(&schemaPath, "schema", "", "LDIF export of the schema and extended rights used to resolve GUIDs in table and explain output")
+	flag.Parse()
+
+	switch opts.output {
+	case outputSDDL, outputJSON, outputTable, outputExplain:
+	default:
+		fatalf("unknown output format %q", opts.output)
+	}
+
+	if schemaPath != "" {
+		resolver, err := loadSchema(schemaPath)
+		if err != nil {
+			fatalf("%s", err)
+		}
+		opts.resolver = resolver
+	}
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+
+	failed := false
+	out := bufio.NewWriter(os.Stdout)
+	for _, path := range paths {
+		if err := processFile(out, path, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "winacl: %s\n", err)
+			failed = true
+		}
+	}
+	out.Flush()
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "winacl: "+format+"\n", args...)
+	os.Exit(2)
+}
+
+func loadSchema(path string) (*winacl.GUIDResolver, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	resolver := winacl.NewGUIDResolver()
+	return resolver, resolver.LoadSchemaLDIF(file)
+}
+
+func processFile(out io.Writer, path string, opts options) error {
+	var rdr io.Reader = os.Stdin
+	name := "stdin"
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		rdr = file
+		name = path
+	}
+
+	if !opts.batch {
+		data, err := ioutil.ReadAll(rdr)
+		if err != nil {
+			return err
+		}
+		raw, err := decodeInput(data, opts.input)
+		if err == nil {
+			err = processDescriptor(out, raw, opts)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	}
+
+	return processBatch(out, rdr, name, opts)
+}
+
+// processBatch decodes every non-empty line of rdr, reporting failures
+// on stderr without stopping at the first one
+func processBatch(out io.Writer, rdr io.Reader, name string, opts options) error {
+	if opts.input == inputRaw {
+		return fmt.Errorf("%s: batch mode requires base64 or hex input", name)
+	}
+
+	failures := 0
+	scanner := bufio.NewScanner(rdr)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		raw, err := decodeLine([]byte(line), opts.input)
+		if err == nil {
+			err = processDescriptor(out, raw, opts)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "winacl: %s:%d: %s\n", name, lineNo, err)
+			failures++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if failures > 0 {
+		return fmt.Errorf("%s: %d descriptors could not be decoded", name, failures)
+	}
+	return nil
+}
+
+func processDescriptor(out io.Writer, raw []byte, opts options) error {
+	ntsd, err := winacl.NewNtSecurityDescriptor(raw)
+	if err != nil {
+		return err
+	}
+
+	return writeDescriptor(out, ntsd, opts)
+}
+
+// decodeInput turns the encoded input into the raw descriptor bytes.
+// In auto mode, input that is entirely base64 or hex text is decoded,
+// anything else is taken to be a raw descriptor
+func decodeInput(data []byte, encoding string) ([]byte, error) {
+	switch encoding {
+	case inputRaw:
+		return data, nil
+	case inputBase64:
+		return decodeBase64(data)
+	case inputHex:
+		return decodeHex(data)
+	case inputAuto:
+		if raw, err := decodeText(data); err == nil {
+			return raw, nil
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unknown input encoding %q", encoding)
+	}
+}
+
+// decodeLine decodes a single batch mode line, which is never taken
+// to be a raw descriptor
+func decodeLine(data []byte, encoding string) ([]byte, error) {
+	if encoding == inputAuto {
+		return decodeText(data)
+	}
+	return decodeInput(data, encoding)
+}
+
+// decodeText decodes hex or base64 text, in that order
+func decodeText(data []byte) ([]byte, error) {
+	if raw, err := decodeHex(data); err == nil {
+		return raw, nil
+	}
+	if raw, err := decodeBase64(data); err == nil {
+		return raw, nil
+	}
+	return nil, fmt.Errorf("input is neither base64 nor hex encoded")
+}
+
+func decodeBase64(data []byte) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(stripSpace(data))
+}
+
+func decodeHex(data []byte) ([]byte, error) {
+	text := strings.TrimPrefix(stripSpace(data), "0x")
+	return hex.DecodeString(text)
+}
+
+func stripSpace(data []byte) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, string(data))
+}
+
+func writeDescriptor(out io.Writer, ntsd winacl.NtSecurityDescriptor, opts options) error {
+	switch opts.output {
+	case outputJSON:
+		encoded, err := json.Marshal(ntsd)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\n", encoded)
+		return err
+	case outputTable:
+		return writeTable(out, ntsd, opts.resolver)
+	case outputExplain:
+		_, err := fmt.Fprint(out, winacl.Explainer{Resolver: opts.resolver}.Explain(ntsd))
+		return err
+	default:
+		_, err := fmt.Fprintln(out, ntsd.ToSDDL())
+		return err
+	}
+}
+
+// writeTable prints the owner, group and one row per DACL entry, with
+// principals and object types resolved to names where possible
+func writeTable(out io.Writer, ntsd winacl.NtSecurityDescriptor, resolver *winacl.GUIDResolver) error {
+	buf := bytes.Buffer{}
+	fmt.Fprintf(&buf, "Owner: %s\n", formatSID(ntsd.Owner))
+	fmt.Fprintf(&buf, "Group: %s\n", formatSID(ntsd.Group))
+	fmt.Fprintf(&buf, "Control: 0x%04x (%s)\n\n", uint16(ntsd.Header.Control), ntsd.Header.Control)
+	if ntsd.DACL == nil {
+		fmt.Fprintln(&buf, "DACL: NULL (everyone has full control)")
+		_, err := out.Write(buf.Bytes())
+		return err
+	}
+
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tTYPE\tFLAGS\tPRINCIPAL\tRIGHTS\tOBJECT TYPE\tINHERITED OBJECT TYPE")
+	for idx, ace := range ntsd.DACL.Aces {
+		principal, objectType, inheritedObjectType := "", "", ""
+		if ace.ObjectAce != nil {
+			principal = formatSID(ace.ObjectAce.GetPrincipal())
+		}
+		if aa, ok := ace.ObjectAce.(winacl.AdvancedAce); ok {
+			if aa.Flags&winacl.ACEInheritanceFlagsObjectTypePresent != 0 {
+				objectType = resolver.Resolve(aa.ObjectType)
+			}
+			if aa.Flags&winacl.ACEInheritanceFlagsInheritedObjectTypePresent != 0 {
+				inheritedObjectType = resolver.Resolve(aa.InheritedObjectType)
+			}
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			idx,
+			ace.GetTypeString(),
+			strings.Join(headerFlagNames(ace.Header.Flags), "|"),
+			principal,
+			strings.Join(ace.AccessMask.StringSlice(), "|"),
+			objectType,
+			inheritedObjectType,
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	buf.WriteString("\n")
+	_, err := out.Write(buf.Bytes())
+	return err
+}
+
+func formatSID(sid winacl.SID) string {
+	sidStr := sid.String()
+	if resolved := sid.Resolve(); resolved != sidStr {
+		return fmt.Sprintf("%s (%s)", resolved, sidStr)
+	}
+	return sidStr
+}
+
+func headerFlagNames(flags winacl.ACEHeaderFlags) []string {
+	names := []string{}
+	for flag := winacl.ACEHeaderFlags(1); flag != 0; flag <<= 1 {
+		if name := winacl.ACEHeaderFlagLookup[flag]; flags&flag != 0 && name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
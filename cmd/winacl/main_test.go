@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readTestDescriptor(t *testing.T) []byte {
+	b64, err := os.ReadFile("../../testdata/ntsd.b64")
+	require.NoError(t, err)
+	raw, err := base64.StdEncoding.DecodeString(string(b64))
+	require.NoError(t, err)
+	return raw
+}
+
+func TestDecodeInput(t *testing.T) {
+
+	r := require.New(t)
+	raw := readTestDescriptor(t)
+
+	t.Run("Detects base64, hex and raw input", func(t *testing.T) {
+		for name, input := range map[string][]byte{
+			"base64": []byte(base64.StdEncoding.EncodeToString(raw) + "\n"),
+			"hex":    []byte("0x" + hex.EncodeToString(raw)),
+			"raw":    raw,
+		} {
+			decoded, err := decodeInput(input, inputAuto)
+			r.NoError(err, name)
+			r.Equal(raw, decoded, name)
+		}
+	})
+
+	t.Run("Rejects undecodable batch lines", func(t *testing.T) {
+		_, err := decodeLine([]byte("not a descriptor"), inputAuto)
+		r.Error(err)
+
+		_, err = decodeInput([]byte("zz"), inputHex)
+		r.Error(err)
+	})
+
+}
+
+func TestProcessBatch(t *testing.T) {
+
+	r := require.New(t)
+	b64 := base64.StdEncoding.EncodeToString(readTestDescriptor(t))
+
+	t.Run("Writes one line per descriptor", func(t *testing.T) {
+		out := bytes.Buffer{}
+		input := strings.NewReader(b64 + "\n\n" + b64 + "\n")
+		r.NoError(processBatch(&out, input, "stdin", options{input: inputAuto, output: outputJSON}))
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		r.Len(lines, 2)
+		r.True(strings.HasPrefix(lines[0], `{"header":`))
+	})
+
+	t.Run("Resolves principals and object types in tables", func(t *testing.T) {
+		out := bytes.Buffer{}
+		r.NoError(processBatch(&out, strings.NewReader(b64), "stdin", options{input: inputBase64, output: outputTable}))
+
+		table := out.String()
+		r.Contains(table, "Owner: Domain Admins (S-1-5-21-2333832797-2102143736-1942374753-512)")
+		r.Contains(table, "User-Change-Password")
+		r.Contains(table, "CONTAINER_INHERIT_ACE|INHERITED_ACE")
+	})
+
+	t.Run("Explains descriptors in sentences", func(t *testing.T) {
+		out := bytes.Buffer{}
+		r.NoError(processBatch(&out, strings.NewReader(b64), "stdin", options{input: inputBase64, output: outputExplain}))
+		r.Contains(out.String(), "Owner: Domain Admins")
+		r.Contains(out.String(), "change password")
+	})
+
+}
@@ -0,0 +1,195 @@
+package winacl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Sizes of the SECURITY_DESCRIPTOR structure heading an absolute
+// descriptor, whose pointers take 4 bytes on 32-bit systems and 8 bytes,
+// after 4 bytes of padding, on 64-bit systems
+const (
+	absoluteHeaderSize32 = 20
+	absoluteHeaderSize64 = 40
+)
+
+// AbsoluteSecurityDescriptor is a security descriptor in absolute format,
+// as found in memory and in some RPC payloads: its header points to the
+// owner, group and ACLs instead of holding their offsets. A nil field is
+// a null pointer. SelfRelative is never set in Control
+type AbsoluteSecurityDescriptor struct {
+	Revision byte
+	Sbz1     byte
+	Control  ControlFlags
+	Owner    *SID
+	Group    *SID
+	SACL     *ACL
+	DACL     *ACL
+}
+
+// AbsoluteComponents are the pieces of an absolute descriptor read from
+// separate locations, e.g. from a memory dump. Header is the
+// SECURITY_DESCRIPTOR structure itself, with 32 or 64-bit pointers, and
+// the other fields the bytes its pointers point to. Components whose
+// pointer is null are ignored
+type AbsoluteComponents struct {
+	Header []byte
+	Owner  []byte
+	Group  []byte
+	SACL   []byte
+	DACL   []byte
+}
+
+// IsSelfRelative reports whether the header describes a self-relative
+// descriptor, as opposed to an absolute one
+func (h NtSecurityDescriptorHeader) IsSelfRelative() bool {
+	return h.Control&SelfRelative != 0
+}
+
+// NewAbsoluteSecurityDescriptor is a constructor that will parse out an
+// AbsoluteSecurityDescriptor from its components. As in Windows, the SACL
+// and DACL are only read when SACLPresent and DACLPresent are set
+func NewAbsoluteSecurityDescriptor(components AbsoluteComponents) (AbsoluteSecurityDescriptor, error) {
+	sd := AbsoluteSecurityDescriptor{}
+
+	pointerSize := 0
+	switch len(components.Header) {
+	case absoluteHeaderSize32:
+		pointerSize = 4
+	case absoluteHeaderSize64:
+		pointerSize = 8
+	default:
+		return sd, AbsoluteSecurityDescriptorInvalidError{fmt.Sprintf(
+			"header is %d bytes, not %d or %d", len(components.Header), absoluteHeaderSize32, absoluteHeaderSize64)}
+	}
+
+	header := components.Header
+	sd.Revision = header[0]
+	sd.Sbz1 = header[1]
+	sd.Control = ControlFlags(binary.LittleEndian.Uint16(header[2:4]))
+	if sd.Control&SelfRelative != 0 {
+		return sd, AbsoluteSecurityDescriptorInvalidError{"header is self-relative"}
+	}
+
+	// Owner, group, SACL and DACL pointers follow the (padded) control
+	pointers := [4]bool{}
+	start := len(header) - 4*pointerSize
+	for idx := range pointers {
+		pointer := header[start+idx*pointerSize : start+(idx+1)*pointerSize]
+		pointers[idx] = !bytes.Equal(pointer, make([]byte, pointerSize))
+	}
+
+	var err error
+	if pointers[0] {
+		if sd.Owner, err = parseAbsoluteSID("owner", components.Owner); err != nil {
+			return sd, err
+		}
+	}
+	if pointers[1] {
+		if sd.Group, err = parseAbsoluteSID("group", components.Group); err != nil {
+			return sd, err
+		}
+	}
+	if pointers[2] && sd.Control&SACLPresent != 0 {
+		if sd.SACL, err = parseAbsoluteACL("SACL", components.SACL); err != nil {
+			return sd, err
+		}
+	}
+	if pointers[3] && sd.Control&DACLPresent != 0 {
+		if sd.DACL, err = parseAbsoluteACL("DACL", components.DACL); err != nil {
+			return sd, err
+		}
+	}
+	return sd, nil
+}
+
+// ToAbsolute converts a self-relative descriptor to absolute format. A
+// NULL DACL or SACL keeps its present bit with a nil pointer
+func (s NtSecurityDescriptor) ToAbsolute() AbsoluteSecurityDescriptor {
+	sd := AbsoluteSecurityDescriptor{
+		Revision: s.Header.Revision,
+		Sbz1:     s.Header.Sbz1,
+		Control:  s.Header.Control &^ SelfRelative,
+	}
+
+	if s.Owner.String() != "" {
+		owner := s.Owner
+		sd.Owner = &owner
+	}
+	if s.Group.String() != "" {
+		group := s.Group
+		sd.Group = &group
+	}
+	if s.SACL.isPresent() {
+		sacl := s.SACL
+		sd.SACL = &sacl
+		sd.Control |= SACLPresent
+	}
+	if s.DACL != nil {
+		dacl := *s.DACL
+		sd.DACL = &dacl
+		sd.Control |= DACLPresent
+	}
+	return sd
+}
+
+// ToSelfRelative converts an absolute descriptor to self-relative
+// format, with offsets and control bits matching what ToBuffer writes
+func (a AbsoluteSecurityDescriptor) ToSelfRelative() (NtSecurityDescriptor, error) {
+	ntsd := NtSecurityDescriptor{
+		Header: NtSecurityDescriptorHeader{
+			Revision: a.Revision,
+			Sbz1:     a.Sbz1,
+			Control:  a.Control,
+		},
+	}
+
+	if a.Owner != nil {
+		ntsd.Owner = *a.Owner
+	}
+	if a.Group != nil {
+		ntsd.Group = *a.Group
+	}
+	if a.SACL != nil {
+		ntsd.SACL = *a.SACL
+	}
+	if a.DACL != nil {
+		dacl := *a.DACL
+		ntsd.DACL = &dacl
+	}
+
+	buf, err := ntsd.ToBuffer()
+	if err != nil {
+		return ntsd, err
+	}
+	return NewNtSecurityDescriptor(buf.Bytes())
+}
+
+type AbsoluteSecurityDescriptorInvalidError struct{ msg string }
+
+func (e AbsoluteSecurityDescriptorInvalidError) Error() string {
+	return fmt.Sprintf("NewAbsoluteSecurityDescriptor: %s", e.msg)
+}
+
+func parseAbsoluteSID(name string, data []byte) (*SID, error) {
+	if len(data) < 8 || len(data) < 8+4*int(data[1]) {
+		return nil, AbsoluteSecurityDescriptorInvalidError{fmt.Sprintf("%s SID is missing or truncated", name)}
+	}
+	sid, err := NewSID(bytes.NewBuffer(data), 8+4*int(data[1]))
+	if err != nil {
+		return nil, err
+	}
+	return &sid, nil
+}
+
+func parseAbsoluteACL(name string, data []byte) (*ACL, error) {
+	if len(data) < 8 {
+		return nil, AbsoluteSecurityDescriptorInvalidError{fmt.Sprintf("%s is missing or truncated", name)}
+	}
+	acl, err := NewACL(bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	return &acl, nil
+}
@@ -0,0 +1,116 @@
+package winacl_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAbsoluteHeader lays out a SECURITY_DESCRIPTOR with 64-bit
+// pointers, using 1 to 4 as the owner, group, SACL and DACL addresses
+func newTestAbsoluteHeader(control winacl.ControlFlags, present [4]bool) []byte {
+	header := make([]byte, 40)
+	header[0] = 1
+	binary.LittleEndian.PutUint16(header[2:4], uint16(control))
+	for idx, isPresent := range present {
+		if isPresent {
+			binary.LittleEndian.PutUint64(header[8+8*idx:], uint64(idx+1))
+		}
+	}
+	return header
+}
+
+func TestAbsoluteSecurityDescriptor(t *testing.T) {
+
+	r := require.New(t)
+
+	t.Run("Converts between self-relative and absolute formats", func(t *testing.T) {
+		ntsd := newTestSD()
+		absolute := ntsd.ToAbsolute()
+		r.Zero(absolute.Control & winacl.SelfRelative)
+		r.NotNil(absolute.DACL)
+		r.Nil(absolute.SACL)
+		r.Equal(ntsd.Owner, *absolute.Owner)
+
+		converted, err := absolute.ToSelfRelative()
+		r.NoError(err)
+		r.True(converted.Header.IsSelfRelative())
+
+		expected, err := ntsd.ToBuffer()
+		r.NoError(err)
+		actual, err := converted.ToBuffer()
+		r.NoError(err)
+		r.Equal(expected.Bytes(), actual.Bytes())
+	})
+
+	t.Run("Keeps NULL ACLs across conversions", func(t *testing.T) {
+		control := winacl.DACLPresent | winacl.SACLPresent
+		absolute := winacl.AbsoluteSecurityDescriptor{Revision: 1, Control: control}
+
+		converted, err := absolute.ToSelfRelative()
+		r.NoError(err)
+		r.Equal(control, converted.Header.Control&control)
+		r.Nil(converted.DACL)
+		r.Zero(converted.Header.OffsetSacl)
+
+		back := converted.ToAbsolute()
+		r.Equal(control, back.Control)
+		r.Nil(back.DACL)
+		r.Nil(back.SACL)
+	})
+
+	t.Run("Parses absolute descriptors from their components", func(t *testing.T) {
+		ntsd := newTestSD()
+		owner, _ := ntsd.Owner.ToBuffer()
+		dacl, _ := ntsd.DACL.ToBuffer()
+
+		control := winacl.DACLPresent | winacl.DACLAutoInherited
+		absolute, err := winacl.NewAbsoluteSecurityDescriptor(winacl.AbsoluteComponents{
+			Header: newTestAbsoluteHeader(control, [4]bool{true, false, false, true}),
+			Owner:  owner.Bytes(),
+			DACL:   dacl.Bytes(),
+		})
+		r.NoError(err)
+		r.Equal(control, absolute.Control)
+		r.Equal(ntsd.Owner.String(), absolute.Owner.String())
+		r.Nil(absolute.Group)
+		r.Len(absolute.DACL.Aces, len(ntsd.DACL.Aces))
+
+		converted, err := absolute.ToSelfRelative()
+		r.NoError(err)
+		r.Equal(ntsd.DACL.Aces, converted.DACL.Aces)
+		r.Equal(winacl.SelfRelative|control, converted.Header.Control)
+	})
+
+	t.Run("Parses 32-bit headers and skips ACLs that are not present", func(t *testing.T) {
+		header := make([]byte, 20)
+		header[0] = 1
+		binary.LittleEndian.PutUint32(header[16:], 0x1000)
+
+		absolute, err := winacl.NewAbsoluteSecurityDescriptor(winacl.AbsoluteComponents{Header: header})
+		r.NoError(err)
+		r.Nil(absolute.DACL)
+	})
+
+	t.Run("Rejects invalid components", func(t *testing.T) {
+		_, err := winacl.NewAbsoluteSecurityDescriptor(winacl.AbsoluteComponents{Header: make([]byte, 12)})
+		r.IsType(winacl.AbsoluteSecurityDescriptorInvalidError{}, err)
+
+		_, err = winacl.NewAbsoluteSecurityDescriptor(winacl.AbsoluteComponents{
+			Header: newTestAbsoluteHeader(winacl.SelfRelative, [4]bool{}),
+		})
+		r.IsType(winacl.AbsoluteSecurityDescriptorInvalidError{}, err)
+
+		_, err = winacl.NewAbsoluteSecurityDescriptor(winacl.AbsoluteComponents{
+			Header: newTestAbsoluteHeader(0, [4]bool{true, false, false, false}),
+		})
+		r.IsType(winacl.AbsoluteSecurityDescriptorInvalidError{}, err)
+	})
+
+	t.Run("Rejects absolute descriptors passed as self-relative", func(t *testing.T) {
+		_, err := winacl.NewNtSecurityDescriptor(make([]byte, 20))
+		r.IsType(winacl.NtSecurityDescriptorInvalidError{}, err)
+	})
+}
@@ -0,0 +1,269 @@
+package winacl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Privileges taken into account by AccessCheck
+const (
+	SeSecurityPrivilege      = "SeSecurityPrivilege"
+	SeTakeOwnershipPrivilege = "SeTakeOwnershipPrivilege"
+	SeBackupPrivilege        = "SeBackupPrivilege"
+	SeRestorePrivilege       = "SeRestorePrivilege"
+)
+
+// ownerRightsSID is OWNER RIGHTS, whose ACEs replace the implicit
+// READ_CONTROL and WRITE_DAC granted to an object's owner
+const ownerRightsSID = "S-1-3-4"
+
+// GenericMapping maps the GENERIC_* bits of an access mask to the
+// object-specific rights they stand for
+type GenericMapping struct {
+	Read    uint32
+	Write   uint32
+	Execute uint32
+	All     uint32
+}
+
+// ADGenericMapping is the generic mapping of Active Directory objects
+var ADGenericMapping = GenericMapping{
+	Read:    AccessMaskReadControl | ADSRightDSListChildrend | ADSRightDSReadProp | ADSRightDSListObject,
+	Write:   AccessMaskReadControl | ADSRightDSSelf | ADSRightDSWriteProp,
+	Execute: AccessMaskReadControl | ADSRightDSListChildrend,
+	All:     adsRightsGenericAll,
+}
+
+// FileGenericMapping is the generic mapping of files and directories
+var FileGenericMapping = GenericMapping{
+	Read:    0x00120089,
+	Write:   0x00120116,
+	Execute: 0x001200A0,
+	All:     FileAllAccess,
+}
+
+// Map replaces the GENERIC_* bits of mask with the rights they map to
+func (g GenericMapping) Map(mask uint32) uint32 {
+	mapped := mask &^ (AccessMaskGenericRead | AccessMaskGenericWrite | AccessMaskGenericExecute | AccessMaskGenericAll)
+	if mask&AccessMaskGenericRead != 0 {
+		mapped |= g.Read
+	}
+	if mask&AccessMaskGenericWrite != 0 {
+		mapped |= g.Write
+	}
+	if mask&AccessMaskGenericExecute != 0 {
+		mapped |= g.Execute
+	}
+	if mask&AccessMaskGenericAll != 0 {
+		mapped |= g.All
+	}
+	return mapped
+}
+
+// Token is the security context access is checked for: a user, the
+// groups it belongs to and the privileges it has enabled
+type Token struct {
+	User       SID
+	Groups     []SID
+	Privileges []string
+}
+
+// NewToken is a constructor for a Token whose groups are computed from
+// the membership graph, including the implicit groups
+func (m *Membership) NewToken(user SID, privileges ...string) Token {
+	return Token{
+		User:       user,
+		Groups:     m.TokenGroups(user),
+		Privileges: privileges,
+	}
+}
+
+// HasSID reports whether the token's user or one of its groups is sid
+func (t Token) HasSID(sid SID) bool {
+	sidStr := sid.String()
+	if t.User.String() == sidStr {
+		return true
+	}
+	for _, group := range t.Groups {
+		if group.String() == sidStr {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPrivilege reports whether the token holds the named privilege,
+// compared case-insensitively
+func (t Token) HasPrivilege(name string) bool {
+	for _, privilege := range t.Privileges {
+		if strings.EqualFold(privilege, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessRequest describes the access a token asks for. Mapping defaults
+// to ADGenericMapping. ObjectType restricts object ACEs to those naming
+// it; object ACEs are skipped when it is the null GUID. BackupSemantics
+// is the backup/restore intent (FILE_FLAG_BACKUP_SEMANTICS), without
+// which SeBackupPrivilege and SeRestorePrivilege are not used
+type AccessRequest struct {
+	Desired         uint32
+	Mapping         GenericMapping
+	ObjectType      GUID
+	BackupSemantics bool
+}
+
+// AccessCheckResult is the outcome of AccessCheck. Missing holds the
+// desired rights that were not granted, and PrivilegesUsed the
+// privileges that granted rights the DACL did not have to
+type AccessCheckResult struct {
+	Allowed        bool
+	Granted        ACEAccessMask
+	Missing        ACEAccessMask
+	PrivilegesUsed []string
+}
+
+// String returns an human-readable representation of an AccessCheckResult
+func (r AccessCheckResult) String() string {
+	verdict := "DENIED"
+	if r.Allowed {
+		verdict = "GRANTED"
+	}
+
+	s := fmt.Sprintf("%s: granted [%s]", verdict, strings.Join(r.Granted.StringSlice(), " "))
+	if r.Missing.Raw() != 0 {
+		s += fmt.Sprintf(" missing [%s]", strings.Join(r.Missing.StringSlice(), " "))
+	}
+	if len(r.PrivilegesUsed) > 0 {
+		s += fmt.Sprintf(" using %s", strings.Join(r.PrivilegesUsed, ", "))
+	}
+	return s
+}
+
+// AccessCheck decides whether a token is granted the desired access to
+// an object, following the Windows rules:
+//
+//   - the owner is granted READ_CONTROL and WRITE_DAC, unless the DACL
+//     holds OWNER RIGHTS ACEs
+//   - the DACL is evaluated in order; a descriptor without a DACL
+//     grants everything, an empty one nothing
+//   - ACCESS_SYSTEM_SECURITY is only granted through SeSecurityPrivilege
+//   - with backup semantics, SeBackupPrivilege grants read access and
+//     SeRestorePrivilege write access, WRITE_DAC and WRITE_OWNER,
+//     regardless of deny ACEs
+//   - SeTakeOwnershipPrivilege grants WRITE_OWNER, regardless of deny ACEs
+//
+// MAXIMUM_ALLOWED requests every right the DACL can grant
+func AccessCheck(ntsd NtSecurityDescriptor, token Token, request AccessRequest) AccessCheckResult {
+	mapping := request.Mapping
+	if mapping == (GenericMapping{}) {
+		mapping = ADGenericMapping
+	}
+
+	// Privileges only grant rights requested explicitly
+	explicit := mapping.Map(request.Desired) &^ AccessMaskMaximumAllowed
+	maximumAllowed := request.Desired&AccessMaskMaximumAllowed != 0
+	desired := explicit
+	if maximumAllowed {
+		desired |= mapping.All
+	}
+
+	// ACCESS_SYSTEM_SECURITY cannot be granted by the DACL
+	var granted uint32
+	dacl := desired &^ AccessMaskSystemSecurity
+	if ntsd.Owner.String() != "" && token.HasSID(ntsd.Owner) && !hasOwnerRightsACE(ntsd.daclAces()) {
+		granted |= dacl & (AccessMaskReadControl | AccessMaskWriteDACL)
+	}
+
+	// A NULL DACL grants everything; an empty one nothing
+	if ntsd.DACL == nil {
+		granted |= dacl
+	} else {
+		granted |= evaluateDACL(*ntsd.DACL, token, ntsd.Owner, mapping, request.ObjectType, dacl&^granted)
+	}
+
+	// Privileges are checked before the DACL by Windows; checking them
+	// after grants the same rights and shows which ones were needed
+	result := AccessCheckResult{}
+	grantByPrivilege := func(privilege string, rights uint32) {
+		if rights&explicit&^granted == 0 || !token.HasPrivilege(privilege) {
+			return
+		}
+		granted |= rights & explicit
+		result.PrivilegesUsed = append(result.PrivilegesUsed, privilege)
+	}
+
+	grantByPrivilege(SeSecurityPrivilege, AccessMaskSystemSecurity)
+	if request.BackupSemantics {
+		grantByPrivilege(SeBackupPrivilege, mapping.Read|mapping.Execute|AccessMaskReadControl|AccessMaskSystemSecurity)
+		grantByPrivilege(SeRestorePrivilege, mapping.Write|AccessMaskWriteDACL|AccessMaskWriteOwner|AccessMaskDelete|AccessMaskSystemSecurity)
+	}
+	grantByPrivilege(SeTakeOwnershipPrivilege, AccessMaskWriteOwner)
+
+	result.Granted = NewACEAccessMask(granted)
+	result.Missing = NewACEAccessMask(desired &^ granted)
+	if maximumAllowed {
+		result.Missing = NewACEAccessMask(explicit &^ granted)
+		result.Allowed = granted != 0 && result.Missing.Raw() == 0
+	} else {
+		result.Allowed = result.Missing.Raw() == 0
+	}
+	return result
+}
+
+// evaluateDACL walks the DACL in order and returns the rights among
+// remaining granted to the token before a deny ACE takes them away.
+// OWNER RIGHTS ACEs apply to the token when it holds the owner SID.
+// Callback allow ACEs are skipped, as their condition cannot be
+// evaluated, while callback deny ACEs are always applied
+func evaluateDACL(dacl ACL, token Token, owner SID, mapping GenericMapping, objectType GUID, remaining uint32) uint32 {
+	var granted, denied uint32
+
+	for _, ace := range dacl.Aces {
+		if ace.ObjectAce == nil || ace.Header.Flags&ACEHeaderFlagsInheritOnlyAce != 0 {
+			continue
+		}
+		if isCallbackACE(ace) && isAllowACE(ace) {
+			continue
+		}
+		if !aceAppliesToToken(ace, token, owner) {
+			continue
+		}
+		if aa, ok := ace.ObjectAce.(AdvancedAce); ok && aa.Flags&ACEInheritanceFlagsObjectTypePresent != 0 {
+			if objectType.IsNull() || aa.ObjectType != objectType {
+				continue
+			}
+		}
+
+		mask := mapping.Map(ace.AccessMask.Raw()) & remaining
+		switch {
+		case isAllowACE(ace):
+			granted |= mask &^ denied
+		case isDenyACE(ace):
+			denied |= mask &^ granted
+		}
+	}
+	return granted
+}
+
+// aceAppliesToToken reports whether the token holds the ACE's trustee,
+// standing in for OWNER RIGHTS when it holds the owner SID
+func aceAppliesToToken(ace ACE, token Token, owner SID) bool {
+	principal := ace.ObjectAce.GetPrincipal()
+	if principal.String() == ownerRightsSID {
+		return owner.String() != "" && token.HasSID(owner)
+	}
+	return token.HasSID(principal)
+}
+
+func hasOwnerRightsACE(aces []ACE) bool {
+	for _, ace := range aces {
+		if ace.ObjectAce != nil && ace.Header.Flags&ACEHeaderFlagsInheritOnlyAce == 0 &&
+			!isAuditACE(ace) && ace.ObjectAce.GetPrincipal().String() == ownerRightsSID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package winacl_test
+
+import (
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessCheck(t *testing.T) {
+
+	r := require.New(t)
+
+	membership, err := newTestMembership()
+	r.NoError(err)
+
+	alice, _ := winacl.ParseSID(testDomainSID + "-1105")
+	helpdesk, _ := winacl.ParseSID(testDomainSID + "-1110")
+	tier1, _ := winacl.ParseSID(testDomainSID + "-1111")
+	domainAdmins, _ := winacl.ParseSID(testDomainSID + "-512")
+	authUsers, _ := winacl.ParseSID("S-1-5-11")
+	ownerRights, _ := winacl.ParseSID("S-1-3-4")
+	userChangePassword, _ := winacl.ParseGUID("ab721a53-1e2f-11d0-9819-00aa0040529b")
+
+	ntsd, err := winacl.NewDescriptor().
+		Owner(domainAdmins).
+		Deny(tier1, winacl.AccessMaskWriteOwner).
+		Allow(helpdesk, winacl.AccessMaskGenericRead).
+		AllowObject(authUsers, winacl.ADSRightDSControlAccess, userChangePassword, winacl.GUID{}).
+		Allow(domainAdmins, winacl.AccessMaskGenericAll).
+		Build()
+	r.NoError(err)
+
+	check := func(token winacl.Token, request winacl.AccessRequest) winacl.AccessCheckResult {
+		return winacl.AccessCheck(ntsd, token, request)
+	}
+
+	t.Run("Grants access through nested groups", func(t *testing.T) {
+		result := check(membership.NewToken(alice), winacl.AccessRequest{Desired: winacl.AccessMaskGenericRead})
+		r.True(result.Allowed)
+		r.Equal(winacl.ADGenericMapping.Read, result.Granted.Raw())
+		r.Empty(result.PrivilegesUsed)
+
+		result = check(membership.NewToken(alice), winacl.AccessRequest{Desired: winacl.ADSRightDSWriteProp})
+		r.False(result.Allowed)
+		r.Equal(uint32(winacl.ADSRightDSWriteProp), result.Missing.Raw())
+	})
+
+	t.Run("Uses SeTakeOwnershipPrivilege over a deny", func(t *testing.T) {
+		request := winacl.AccessRequest{Desired: winacl.AccessMaskWriteOwner}
+		r.False(check(membership.NewToken(alice), request).Allowed)
+
+		result := check(membership.NewToken(alice, winacl.SeTakeOwnershipPrivilege), request)
+		r.True(result.Allowed)
+		r.Equal([]string{winacl.SeTakeOwnershipPrivilege}, result.PrivilegesUsed)
+	})
+
+	t.Run("Requires SeSecurityPrivilege for ACCESS_SYSTEM_SECURITY", func(t *testing.T) {
+		request := winacl.AccessRequest{Desired: winacl.AccessMaskSystemSecurity | winacl.AccessMaskReadControl}
+		result := check(winacl.Token{User: domainAdmins}, request)
+		r.False(result.Allowed)
+		r.Equal(uint32(winacl.AccessMaskSystemSecurity), result.Missing.Raw())
+
+		result = check(winacl.Token{User: domainAdmins, Privileges: []string{"sesecurityprivilege"}}, request)
+		r.True(result.Allowed)
+		r.Equal([]string{winacl.SeSecurityPrivilege}, result.PrivilegesUsed)
+	})
+
+	t.Run("Uses backup and restore privileges only with backup semantics", func(t *testing.T) {
+		token := membership.NewToken(alice, winacl.SeBackupPrivilege, winacl.SeRestorePrivilege)
+		request := winacl.AccessRequest{Desired: winacl.AccessMaskWriteDACL | winacl.AccessMaskReadControl}
+		r.False(check(token, request).Allowed)
+
+		request.BackupSemantics = true
+		result := check(token, request)
+		r.True(result.Allowed)
+		r.Equal([]string{winacl.SeRestorePrivilege}, result.PrivilegesUsed)
+	})
+
+	t.Run("Grants the owner READ_CONTROL and WRITE_DAC unless OWNER RIGHTS is present", func(t *testing.T) {
+		owned := ntsd
+		owned.Owner = alice
+		dacl := *ntsd.DACL
+		dacl.Aces = append([]winacl.ACE{}, dacl.Aces...)
+		owned.DACL = &dacl
+		request := winacl.AccessRequest{Desired: winacl.AccessMaskReadControl | winacl.AccessMaskWriteDACL}
+		r.True(winacl.AccessCheck(owned, membership.NewToken(alice), request).Allowed)
+
+		r.NoError(owned.DACL.AddAce(winacl.BuildBasicAce(winacl.AceTypeAccessAllowed, 0, winacl.AccessMaskReadControl, ownerRights)))
+		result := winacl.AccessCheck(owned, membership.NewToken(alice), request)
+		r.False(result.Allowed)
+		r.Equal(uint32(winacl.AccessMaskWriteDACL), result.Missing.Raw())
+	})
+
+	t.Run("Applies OWNER RIGHTS ACEs to the owner", func(t *testing.T) {
+		owned, err := winacl.NewDescriptor().
+			Owner(alice).
+			Allow(ownerRights, winacl.AccessMaskReadControl|winacl.AccessMaskWriteDACL).
+			Build()
+		r.NoError(err)
+
+		request := winacl.AccessRequest{Desired: winacl.AccessMaskWriteDACL}
+		r.True(winacl.AccessCheck(owned, membership.NewToken(alice), request).Allowed)
+		r.False(winacl.AccessCheck(owned, membership.NewToken(helpdesk), request).Allowed)
+	})
+
+	t.Run("Ignores callback allow ACEs but applies callback deny ACEs", func(t *testing.T) {
+		request := winacl.AccessRequest{Desired: winacl.AccessMaskReadControl}
+		conditional := winacl.NtSecurityDescriptor{DACL: &winacl.ACL{Aces: []winacl.ACE{
+			winacl.BuildBasicAce(winacl.AceTypeAccessAllowedCallback, 0, winacl.AccessMaskReadControl, alice),
+		}}}
+		r.False(winacl.AccessCheck(conditional, membership.NewToken(alice), request).Allowed)
+
+		conditional.DACL.Aces = []winacl.ACE{
+			winacl.BuildBasicAce(winacl.AceTypeAccessDeniedCallback, 0, winacl.AccessMaskReadControl, alice),
+			winacl.BuildBasicAce(winacl.AceTypeAccessAllowed, 0, winacl.AccessMaskReadControl, alice),
+		}
+		r.False(winacl.AccessCheck(conditional, membership.NewToken(alice), request).Allowed)
+
+		conditional.DACL.Aces = conditional.DACL.Aces[1:]
+		r.True(winacl.AccessCheck(conditional, membership.NewToken(alice), request).Allowed)
+	})
+
+	t.Run("Matches object ACEs against the requested object type", func(t *testing.T) {
+		request := winacl.AccessRequest{Desired: winacl.ADSRightDSControlAccess}
+		r.False(check(membership.NewToken(alice), request).Allowed)
+
+		request.ObjectType = userChangePassword
+		r.True(check(membership.NewToken(alice), request).Allowed)
+	})
+
+	t.Run("Handles missing and empty DACLs", func(t *testing.T) {
+		request := winacl.AccessRequest{Desired: winacl.AccessMaskGenericAll, Mapping: winacl.FileGenericMapping}
+		result := winacl.AccessCheck(winacl.NtSecurityDescriptor{}, membership.NewToken(alice), request)
+		r.True(result.Allowed)
+		r.Equal(uint32(winacl.FileAllAccess), result.Granted.Raw())
+
+		empty, err := winacl.NewDescriptor().EmptyDACL().Build()
+		r.NoError(err)
+		r.False(winacl.AccessCheck(empty, membership.NewToken(alice), request).Allowed)
+	})
+
+	t.Run("Computes MAXIMUM_ALLOWED from the DACL", func(t *testing.T) {
+		result := check(membership.NewToken(alice), winacl.AccessRequest{Desired: winacl.AccessMaskMaximumAllowed})
+		r.True(result.Allowed)
+		r.Equal(winacl.ADGenericMapping.Read, result.Granted.Raw())
+		r.Contains(result.String(), "GRANTED")
+	})
+
+}
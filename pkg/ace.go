@@ -1,6 +1,8 @@
 package winacl
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
 	"strings"
 
@@ -94,6 +96,12 @@ type ACEAccessMask struct {
 	value uint32
 }
 
+// NewACEAccessMask is a constructor for an ACEAccessMask holding
+// the given rights
+func NewACEAccessMask(value uint32) ACEAccessMask {
+	return ACEAccessMask{value: value}
+}
+
 const (
 	AccessMaskGenericRead    = 0x80000000
 	AccessMaskGenericWrite   = 0x40000000
@@ -135,9 +143,12 @@ var ACEAccessMaskLookup = map[uint32]string{
 
 	// Advanced ACEs
 	ADSRightDSControlAccess: "CONTROL_ACCESS",
+	ADSRightDSListObject:    "LIST_OBJECT",
+	ADSRightDSDeleteTree:    "DELETE_TREE",
 	ADSRightDSWriteProp:     "WRITE_PROP",
 	ADSRightDSReadProp:      "READ_PROP",
 	ADSRightDSSelf:          "SELF",
+	ADSRightDSListChildrend: "LIST_CHILDREN",
 	ADSRightDSDeleteChild:   "DELETE_CHILD",
 	ADSRightDSCreateChild:   "CREATE_CHILD",
 }
@@ -283,3 +294,69 @@ func (s AdvancedAce) FlagsString() string {
 type ObjectAce interface {
 	GetPrincipal() SID
 }
+
+// Size returns the length of an ACE's binary representation
+func (s ACE) Size() int {
+	switch ace := s.ObjectAce.(type) {
+	case BasicAce:
+		return 8 + ace.SecurityIdentifier.Size()
+	case AdvancedAce:
+		size := 12 + ace.SecurityIdentifier.Size()
+		if ace.Flags&ACEInheritanceFlagsObjectTypePresent != 0 {
+			size += 16
+		}
+		if ace.Flags&ACEInheritanceFlagsInheritedObjectTypePresent != 0 {
+			size += 16
+		}
+		return size
+	default:
+		return int(s.Header.Size)
+	}
+}
+
+// ToBuffer serializes an ACE to its binary representation. The size
+// written to the header is computed from the ACE's contents
+func (s ACE) ToBuffer() (bytes.Buffer, error) {
+	buf := bytes.Buffer{}
+	size := s.Size()
+	if size > 0xFFFF {
+		return buf, fmt.Errorf("ACE.ToBuffer: ACE size %d exceeds the 65535 byte limit", size)
+	}
+
+	header := s.Header
+	header.Size = uint16(size)
+	if err := binary.Write(&buf, binary.LittleEndian, header); err != nil {
+		return buf, err
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, s.AccessMask.value); err != nil {
+		return buf, err
+	}
+
+	var sid SID
+	switch ace := s.ObjectAce.(type) {
+	case BasicAce:
+		sid = ace.SecurityIdentifier
+	case AdvancedAce:
+		sid = ace.SecurityIdentifier
+		if err := binary.Write(&buf, binary.LittleEndian, ace.Flags); err != nil {
+			return buf, err
+		}
+		if ace.Flags&ACEInheritanceFlagsObjectTypePresent != 0 {
+			guid, _ := ace.ObjectType.MarshalBinary()
+			buf.Write(guid)
+		}
+		if ace.Flags&ACEInheritanceFlagsInheritedObjectTypePresent != 0 {
+			guid, _ := ace.InheritedObjectType.MarshalBinary()
+			buf.Write(guid)
+		}
+	default:
+		return buf, fmt.Errorf("ACE.ToBuffer: unsupported ACE type %s", s.GetTypeString())
+	}
+
+	sidBuf, err := sid.ToBuffer()
+	if err != nil {
+		return buf, err
+	}
+	buf.Write(sidBuf.Bytes())
+	return buf, nil
+}
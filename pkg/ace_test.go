@@ -27,3 +27,32 @@ func TestNewACEHeader(t *testing.T) {
 	})
 
 }
+
+func TestRightsString(t *testing.T) {
+
+	r := require.New(t)
+	everyone, _ := winacl.ParseSID("S-1-1-0")
+	rights := func(mask uint32) string {
+		return winacl.BuildBasicAce(winacl.AceTypeAccessAllowed, 0, mask, everyone).RightsString()
+	}
+
+	t.Run("Writes one symbol per right", func(t *testing.T) {
+		r.Equal("RPLORC", rights(winacl.AccessMaskReadControl|winacl.ADSRightDSReadProp|winacl.ADSRightDSListObject))
+		r.Equal("GA", rights(winacl.AccessMaskGenericAll))
+		r.Equal("", rights(0))
+	})
+
+	t.Run("Uses file and key aliases for whole masks", func(t *testing.T) {
+		r.Equal("FA", rights(winacl.FileAllAccess))
+		r.Equal("FR", rights(winacl.FileGenericMapping.Read))
+		r.Equal("KA", rights(0x000F003F))
+		r.Equal("KR", rights(0x00020019))
+	})
+
+	t.Run("Falls back to a hex mask instead of dropping rights", func(t *testing.T) {
+		r.Equal("0x100000", rights(winacl.AccessMaskSynchronize))
+		r.Equal("0x1200a9", rights(winacl.FileGenericMapping.Read|winacl.FileGenericMapping.Execute))
+		r.Equal("0x2000000", rights(winacl.AccessMaskMaximumAllowed))
+	})
+
+}
@@ -3,8 +3,18 @@ package winacl
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 )
 
+// ACL revisions. ACLRevisionDS is required once an ACL holds object ACEs
+const (
+	ACLRevision   = 2
+	ACLRevisionDS = 4
+)
+
+// maxACLSize is the largest size an ACL header can describe
+const maxACLSize = 0xFFFF
+
 // ACL represents an Access Control List
 type ACL struct {
 	Header ACLHeader
@@ -70,3 +80,67 @@ func (header *ACLHeader) ToBuffer() (bytes.Buffer, error) {
 	err := binary.Write(&buf, binary.LittleEndian, header)
 	return buf, err
 }
+
+// Size returns the length of an ACL's binary representation
+func (a ACL) Size() int {
+	size := 8
+	for _, ace := range a.Aces {
+		size += ace.Size()
+	}
+	return size
+}
+
+// ToBuffer serializes an ACL to its binary representation. The size
+// and ACE count written to the header are computed from its ACEs
+func (a ACL) ToBuffer() (bytes.Buffer, error) {
+	header, err := a.computeHeader()
+	if err != nil {
+		return bytes.Buffer{}, err
+	}
+
+	buf, err := header.ToBuffer()
+	if err != nil {
+		return buf, err
+	}
+	for _, ace := range a.Aces {
+		aceBuf, err := ace.ToBuffer()
+		if err != nil {
+			return buf, err
+		}
+		buf.Write(aceBuf.Bytes())
+	}
+	return buf, nil
+}
+
+// computeHeader returns the ACL's header with its size and ACE count
+// matching its ACEs, and its revision raised to ACLRevisionDS if it
+// holds object ACEs
+func (a ACL) computeHeader() (ACLHeader, error) {
+	header := a.Header
+	size := a.Size()
+	if size > maxACLSize {
+		return header, ACLTooLargeError{Size: size}
+	}
+
+	if header.Revision < ACLRevision {
+		header.Revision = ACLRevision
+	}
+	for _, ace := range a.Aces {
+		if _, ok := ace.ObjectAce.(AdvancedAce); ok {
+			header.Revision = ACLRevisionDS
+			break
+		}
+	}
+
+	header.Size = uint16(size)
+	header.AceCount = uint16(len(a.Aces))
+	return header, nil
+}
+
+// ACLTooLargeError is returned when the ACEs of an ACL do not fit in
+// the 64KB its header can describe
+type ACLTooLargeError struct{ Size int }
+
+func (e ACLTooLargeError) Error() string {
+	return fmt.Sprintf("ACL size %d exceeds the %d byte limit", e.Size, maxACLSize)
+}
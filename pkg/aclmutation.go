@@ -0,0 +1,119 @@
+package winacl
+
+import (
+	"fmt"
+)
+
+// AddAce inserts an ACE at its canonical position: explicit deny ACEs
+// first, then the remaining explicit ACEs, then inherited ACEs. The ACE
+// is placed after the existing ACEs of its group. The ACL is left
+// unchanged if the ACE would take it over the 64KB limit
+func (a *ACL) AddAce(ace ACE) error {
+	ace.Header.Size = uint16(ace.Size())
+
+	rank := canonicalRank(ace)
+	pos := len(a.Aces)
+	for idx, existing := range a.Aces {
+		if canonicalRank(existing) > rank {
+			pos = idx
+			break
+		}
+	}
+
+	aces := make([]ACE, 0, len(a.Aces)+1)
+	aces = append(aces, a.Aces[:pos]...)
+	aces = append(aces, ace)
+	aces = append(aces, a.Aces[pos:]...)
+	return a.setAces(aces)
+}
+
+// ReplaceAce replaces the ACE at index. The ACL is left unchanged if the
+// new ACE would take it over the 64KB limit
+func (a *ACL) ReplaceAce(index int, ace ACE) error {
+	if index < 0 || index >= len(a.Aces) {
+		return fmt.Errorf("ACL.ReplaceAce: index %d out of range", index)
+	}
+
+	ace.Header.Size = uint16(ace.Size())
+	aces := append([]ACE{}, a.Aces...)
+	aces[index] = ace
+	return a.setAces(aces)
+}
+
+// RemoveAces removes every ACE selected by the filter and returns the
+// number of ACEs removed. Unlike FindAces, a nil filter removes nothing;
+// use Clear to remove every ACE
+func (a *ACL) RemoveAces(filter ACEFilter) int {
+	if filter == nil {
+		return 0
+	}
+
+	kept := make([]ACE, 0, len(a.Aces))
+	for _, ace := range a.Aces {
+		if !filter(ace) {
+			kept = append(kept, ace)
+		}
+	}
+
+	removed := len(a.Aces) - len(kept)
+	a.setAces(kept)
+	return removed
+}
+
+// Clear removes every ACE and returns the number of ACEs removed. A DACL
+// left empty denies all access to everyone but the object's owner
+func (a *ACL) Clear() int {
+	removed := len(a.Aces)
+	a.setAces([]ACE{})
+	return removed
+}
+
+// RemovePrincipal removes every ACE whose trustee is sid and returns
+// the number of ACEs removed
+func (a *ACL) RemovePrincipal(sid SID) int {
+	return a.RemoveAces(PrincipalIs(sid))
+}
+
+// RemoveInherited removes every inherited ACE, as done when inheritance
+// is disabled on an object without copying the inherited entries, and
+// returns the number of ACEs removed
+func (a *ACL) RemoveInherited() int {
+	return a.RemoveAces(Inherited())
+}
+
+// setAces replaces the ACL's ACEs and recomputes its header, choosing
+// ACLRevisionDS only when the ACL holds object ACEs
+func (a *ACL) setAces(aces []ACE) error {
+	updated := ACL{Header: a.Header, Aces: aces}
+	updated.Header.Revision = ACLRevision
+
+	header, err := updated.computeHeader()
+	if err != nil {
+		return err
+	}
+
+	a.Header = header
+	a.Aces = aces
+	return nil
+}
+
+// canonicalRank orders ACEs as Windows does when writing a DACL
+func canonicalRank(ace ACE) int {
+	switch {
+	case ace.Header.Flags&ACEHeaderFlagsInheritedAce != 0:
+		return 2
+	case isDenyACE(ace):
+		return 0
+	default:
+		return 1
+	}
+}
+
+// isDenyACE reports whether an ACE denies access
+func isDenyACE(ace ACE) bool {
+	switch ace.Header.Type {
+	case AceTypeAccessDenied, AceTypeAccessDeniedObject, AceTypeAccessDeniedCallback, AceTypeAccessDeniedCallbackObject:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package winacl_test
+
+import (
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACLMutation(t *testing.T) {
+
+	r := require.New(t)
+
+	everyone, _ := winacl.ParseSID("S-1-1-0")
+	authUsers, _ := winacl.ParseSID("S-1-5-11")
+	system, _ := winacl.ParseSID("S-1-5-18")
+	userChangePassword, _ := winacl.ParseGUID("ab721a53-1e2f-11d0-9819-00aa0040529b")
+
+	requireConsistent := func(acl winacl.ACL) {
+		r.Equal(len(acl.Aces), int(acl.Header.AceCount))
+		r.Equal(acl.Size(), int(acl.Header.Size))
+	}
+
+	t.Run("Adds ACEs at their canonical position", func(t *testing.T) {
+		acl := winacl.ACL{}
+		r.NoError(acl.AddAce(winacl.BuildBasicAce(winacl.AceTypeAccessAllowed, winacl.ACEHeaderFlagsInheritedAce, winacl.AccessMaskReadControl, system)))
+		r.NoError(acl.AddAce(winacl.BuildBasicAce(winacl.AceTypeAccessAllowed, 0, winacl.AccessMaskReadControl, authUsers)))
+		r.NoError(acl.AddAce(winacl.BuildBasicAce(winacl.AceTypeAccessDenied, 0, winacl.AccessMaskDelete, everyone)))
+		r.NoError(acl.AddAce(winacl.BuildBasicAce(winacl.AceTypeAccessAllowed, 0, winacl.AccessMaskWriteDACL, system)))
+
+		r.Equal("D:(D;;SD;;;WD)(A;;RC;;;AU)(A;;WD;;;SY)(A;ID;RC;;;SY)", acl.ToSDDL(""))
+		r.Equal(uint8(winacl.ACLRevision), acl.Header.Revision)
+		requireConsistent(acl)
+
+		r.NoError(acl.AddAce(winacl.BuildObjectAce(winacl.AceTypeAccessDeniedObject, 0, winacl.ADSRightDSControlAccess, everyone, userChangePassword, winacl.GUID{})))
+		r.Len(acl.FilterAces(winacl.TypeIs(winacl.AceTypeAccessDeniedObject)), 1)
+		r.Equal(winacl.AceTypeAccessDeniedObject, acl.Aces[1].Header.Type)
+		r.Equal(uint8(winacl.ACLRevisionDS), acl.Header.Revision)
+		requireConsistent(acl)
+	})
+
+	t.Run("Removes ACEs and lowers the revision", func(t *testing.T) {
+		acl := *newTestSD().DACL
+		total := len(acl.Aces)
+
+		removed := acl.RemoveInherited()
+		r.Equal(24, total-removed)
+		requireConsistent(acl)
+
+		r.Equal(1, acl.RemovePrincipal(everyone))
+		r.Equal(0, acl.RemovePrincipal(everyone))
+		requireConsistent(acl)
+
+		acl.RemoveAces(winacl.TypeIs(winacl.AceTypeAccessAllowedObject))
+		r.Equal(uint8(winacl.ACLRevision), acl.Header.Revision)
+		requireConsistent(acl)
+
+		remaining := len(acl.Aces)
+		r.Equal(0, acl.RemoveAces(nil))
+		r.Len(acl.Aces, remaining)
+
+		r.Equal(remaining, acl.Clear())
+		r.Empty(acl.Aces)
+		r.Equal(uint16(8), acl.Header.Size)
+	})
+
+	t.Run("Replaces ACEs in place", func(t *testing.T) {
+		acl := *newTestSD().DACL
+		r.NoError(acl.ReplaceAce(0, winacl.BuildBasicAce(winacl.AceTypeAccessAllowed, 0, winacl.AccessMaskReadControl, everyone)))
+		r.Equal("(A;;RC;;;WD)", acl.Aces[0].ToSDDL())
+		requireConsistent(acl)
+
+		r.Error(acl.ReplaceAce(len(acl.Aces), acl.Aces[0]))
+	})
+
+	t.Run("Rejects ACLs over 64KB", func(t *testing.T) {
+		acl := winacl.ACL{}
+		ace := winacl.BuildBasicAce(winacl.AceTypeAccessAllowed, 0, winacl.AccessMaskReadControl, everyone)
+
+		var err error
+		for i := 0; err == nil; i++ {
+			err = acl.AddAce(ace)
+		}
+		r.IsType(winacl.ACLTooLargeError{}, err)
+		r.LessOrEqual(acl.Size(), 0xFFFF)
+		requireConsistent(acl)
+	})
+
+}
@@ -0,0 +1,144 @@
+package winacl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Audit policy categories under which access events are logged
+const (
+	AuditCategoryDSAccess     = "DS Access"
+	AuditCategoryObjectAccess = "Object Access"
+)
+
+// Event IDs of the predicted audit events
+const (
+	// AuditEventDSAccess is "An operation was performed on an object"
+	AuditEventDSAccess = 4662
+	// AuditEventHandleRequested is "A handle to an object was requested"
+	AuditEventHandleRequested = 4656
+)
+
+// AuditRequest describes an access attempt whose audit events are to be
+// predicted: the token, what it asked for and the access check outcome.
+// ObjectClass is the lDAPDisplayName of a directory object and is left
+// empty for other objects, such as files
+type AuditRequest struct {
+	Token       Token
+	Access      AccessRequest
+	Result      AccessCheckResult
+	ObjectClass string
+}
+
+// AuditEvent is an event Windows would write to the security log. Rights
+// are the audited rights, and AceIndexes the SACL entries that caused
+// the event
+type AuditEvent struct {
+	EventID    int
+	Category   string
+	Success    bool
+	Rights     ACEAccessMask
+	AceIndexes []int
+}
+
+// String returns an human-readable representation of an AuditEvent
+func (e AuditEvent) String() string {
+	outcome := "Failure"
+	if e.Success {
+		outcome = "Success"
+	}
+	return fmt.Sprintf("%d %s %s: %s", e.EventID, e.Category, outcome, strings.Join(e.Rights.StringSlice(), " "))
+}
+
+// PredictAuditEvent returns the event Windows would generate for an
+// access attempt, if any. A SYSTEM_AUDIT ACE takes part when its trustee
+// is in the token, it applies to the object (it is not inherit-only and
+// its InheritedObjectType, if any, names the object's class or one of
+// its superclasses) and:
+//
+//   - for a granted access, it carries SUCCESSFUL_ACCESS_ACE_FLAG and
+//     audits one of the granted rights
+//   - for a denied access, it carries FAILED_ACCESS_ACE_FLAG and audits
+//     one of the requested rights
+//
+// Object audit ACEs only take part when their ObjectType is the
+// requested object type. All matching ACEs contribute to a single event.
+// Classes are resolved with the built-in GUID tables
+func PredictAuditEvent(ntsd NtSecurityDescriptor, request AuditRequest) (AuditEvent, bool) {
+	var builtin *GUIDResolver
+	return builtin.PredictAuditEvent(ntsd, request)
+}
+
+// PredictAuditEvent predicts the event of an access attempt, matching
+// InheritedObjectTypes against the class and superclasses of the loaded
+// schema. ACEs are kept when the class is unknown, as with Edges
+func (r *GUIDResolver) PredictAuditEvent(ntsd NtSecurityDescriptor, request AuditRequest) (AuditEvent, bool) {
+	mapping := request.Access.Mapping
+	if mapping == (GenericMapping{}) {
+		mapping = ADGenericMapping
+	}
+
+	success := request.Result.Allowed
+	candidates := request.Result.Granted.Raw()
+	if !success {
+		candidates = mapping.Map(request.Access.Desired) &^ AccessMaskMaximumAllowed
+	}
+
+	event := AuditEvent{
+		EventID:  AuditEventHandleRequested,
+		Category: AuditCategoryObjectAccess,
+		Success:  success,
+	}
+	if request.ObjectClass != "" {
+		event.EventID = AuditEventDSAccess
+		event.Category = AuditCategoryDSAccess
+	}
+
+	class := strings.ToLower(request.ObjectClass)
+	var audited uint32
+	for idx, ace := range ntsd.SACL.Aces {
+		if !isAuditACE(ace) || ace.Header.Flags&ACEHeaderFlagsInheritOnlyAce != 0 {
+			continue
+		}
+		if success && ace.Header.Flags&ACEHeaderFlagsSuccessfulAccessAceFlag == 0 {
+			continue
+		}
+		if !success && ace.Header.Flags&ACEHeaderFlagsFailedAccessAceFlag == 0 {
+			continue
+		}
+		if !request.Token.HasSID(ace.ObjectAce.GetPrincipal()) {
+			continue
+		}
+		if class != "" && !r.inheritedObjectTypeMatches(ace, class) {
+			continue
+		}
+		if aa, ok := ace.ObjectAce.(AdvancedAce); ok && aa.Flags&ACEInheritanceFlagsObjectTypePresent != 0 {
+			if request.Access.ObjectType.IsNull() || aa.ObjectType != request.Access.ObjectType {
+				continue
+			}
+		}
+
+		if matched := mapping.Map(ace.AccessMask.Raw()) & candidates; matched != 0 {
+			audited |= matched
+			event.AceIndexes = append(event.AceIndexes, idx)
+		}
+	}
+
+	if audited == 0 {
+		return AuditEvent{}, false
+	}
+	event.Rights = NewACEAccessMask(audited)
+	return event, true
+}
+
+// isAuditACE reports whether an ACE is a SYSTEM_AUDIT ACE
+func isAuditACE(ace ACE) bool {
+	if ace.ObjectAce == nil {
+		return false
+	}
+	switch ace.Header.Type {
+	case AceTypeSystemAudit, AceTypeSystemAuditObject, AceTypeSystemAuditCallback, AceTypeSystemAuditCallbackObject:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package winacl_test
+
+import (
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredictAuditEvent(t *testing.T) {
+
+	r := require.New(t)
+
+	membership, err := newTestMembership()
+	r.NoError(err)
+
+	alice, _ := winacl.ParseSID(testDomainSID + "-1105")
+	helpdesk, _ := winacl.ParseSID(testDomainSID + "-1110")
+	everyone, _ := winacl.ParseSID("S-1-1-0")
+	userChangePassword, _ := winacl.ParseGUID("ab721a53-1e2f-11d0-9819-00aa0040529b")
+	group, _ := winacl.ParseGUID("bf967a9c-0de6-11d0-a285-00aa003049e2")
+
+	ntsd, err := winacl.NewDescriptor().
+		Allow(helpdesk, winacl.AccessMaskGenericRead|winacl.AccessMaskWriteDACL).
+		AllowObject(everyone, winacl.ADSRightDSControlAccess, userChangePassword, winacl.GUID{}).
+		Audit(everyone, winacl.AccessMaskWriteDACL|winacl.AccessMaskWriteOwner, winacl.ACEHeaderFlagsSuccessfulAccessAceFlag, winacl.ACEHeaderFlagsFailedAccessAceFlag).
+		Audit(helpdesk, winacl.AccessMaskGenericWrite, winacl.ACEHeaderFlagsFailedAccessAceFlag).
+		Audit(everyone, winacl.AccessMaskGenericAll, winacl.ACEHeaderFlagsSuccessfulAccessAceFlag, winacl.ACEHeaderFlagsInheritOnlyAce).
+		AuditObject(everyone, winacl.ADSRightDSControlAccess, userChangePassword, winacl.GUID{}, winacl.ACEHeaderFlagsSuccessfulAccessAceFlag).
+		AuditObject(everyone, winacl.ADSRightDSReadProp, winacl.GUID{}, group, winacl.ACEHeaderFlagsSuccessfulAccessAceFlag).
+		Build()
+	r.NoError(err)
+
+	predict := func(access winacl.AccessRequest) (winacl.AuditEvent, bool) {
+		token := membership.NewToken(alice)
+		return winacl.PredictAuditEvent(ntsd, winacl.AuditRequest{
+			Token:       token,
+			Access:      access,
+			Result:      winacl.AccessCheck(ntsd, token, access),
+			ObjectClass: winacl.ObjectClassUser,
+		})
+	}
+
+	t.Run("Audits successful accesses to audited rights", func(t *testing.T) {
+		event, found := predict(winacl.AccessRequest{Desired: winacl.AccessMaskWriteDACL | winacl.AccessMaskReadControl})
+		r.True(found)
+		r.True(event.Success)
+		r.Equal(winacl.AuditEventDSAccess, event.EventID)
+		r.Equal(winacl.AuditCategoryDSAccess, event.Category)
+		r.Equal(uint32(winacl.AccessMaskWriteDACL), event.Rights.Raw())
+		r.Equal([]int{0}, event.AceIndexes)
+
+		_, found = predict(winacl.AccessRequest{Desired: winacl.ADSRightDSReadProp})
+		r.False(found)
+	})
+
+	t.Run("Audits failures against the requested rights", func(t *testing.T) {
+		event, found := predict(winacl.AccessRequest{Desired: winacl.ADSRightDSWriteProp | winacl.AccessMaskWriteOwner})
+		r.True(found)
+		r.False(event.Success)
+		r.Equal(uint32(winacl.ADSRightDSWriteProp|winacl.AccessMaskWriteOwner), event.Rights.Raw())
+		r.Equal([]int{0, 1}, event.AceIndexes)
+	})
+
+	t.Run("Matches object audit ACEs on the requested object type", func(t *testing.T) {
+		event, found := predict(winacl.AccessRequest{Desired: winacl.ADSRightDSControlAccess, ObjectType: userChangePassword})
+		r.True(found)
+		r.Equal([]int{3}, event.AceIndexes)
+	})
+
+	t.Run("Matches audit ACEs scoped to a superclass", func(t *testing.T) {
+		user, _ := winacl.ParseGUID("bf967aba-0de6-11d0-a285-00aa003049e2")
+		scoped, err := winacl.NewDescriptor().
+			Allow(everyone, winacl.AccessMaskWriteDACL).
+			AuditObject(everyone, winacl.AccessMaskWriteDACL, winacl.GUID{}, user, winacl.ACEHeaderFlagsSuccessfulAccessAceFlag).
+			Build()
+		r.NoError(err)
+
+		token := membership.NewToken(alice)
+		access := winacl.AccessRequest{Desired: winacl.AccessMaskWriteDACL}
+		request := winacl.AuditRequest{
+			Token:       token,
+			Access:      access,
+			Result:      winacl.AccessCheck(scoped, token, access),
+			ObjectClass: "inetOrgPerson",
+		}
+		event, found := winacl.PredictAuditEvent(scoped, request)
+		r.True(found)
+		r.Equal([]int{0}, event.AceIndexes)
+
+		request.ObjectClass = winacl.ObjectClassGroup
+		_, found = winacl.PredictAuditEvent(scoped, request)
+		r.False(found)
+	})
+
+	t.Run("Uses the object access category outside of the directory", func(t *testing.T) {
+		token := membership.NewToken(alice)
+		access := winacl.AccessRequest{Desired: winacl.AccessMaskWriteOwner, Mapping: winacl.FileGenericMapping}
+		event, found := winacl.PredictAuditEvent(ntsd, winacl.AuditRequest{
+			Token:  token,
+			Access: access,
+			Result: winacl.AccessCheck(ntsd, token, access),
+		})
+		r.True(found)
+		r.Equal(winacl.AuditEventHandleRequested, event.EventID)
+		r.Equal("4656 Object Access Failure: WRITE_OWNER", event.String())
+	})
+
+}
@@ -0,0 +1,167 @@
+package winacl
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CriticalAuditRights are the changes every sensitive object is expected
+// to audit: property writes, owner changes and DACL changes
+const CriticalAuditRights = ADSRightDSWriteProp | AccessMaskWriteOwner | AccessMaskWriteDACL
+
+// AuditCoveragePrincipals are the trustees whose audit ACEs cover every
+// user, and so count towards an object's coverage
+var AuditCoveragePrincipals = map[string]bool{
+	"S-1-1-0":  true, // Everyone
+	"S-1-5-11": true, // Authenticated Users
+}
+
+// AuditCoverageReport summarizes the auditing configured on a set of
+// directory objects
+type AuditCoverageReport struct {
+	Objects []ObjectAuditCoverage `json:"objects"`
+}
+
+// ObjectAuditCoverage lists the audit ACEs applying to an object, and
+// the CriticalAuditRights whose successful use is not audited for all
+// users
+type ObjectAuditCoverage struct {
+	DN          string        `json:"dn"`
+	ObjectClass string        `json:"objectClass"`
+	Audits      []AuditEntry  `json:"audits"`
+	Missing     ACEAccessMask `json:"missing"`
+}
+
+// AuditEntry is an audit ACE applying to an object
+type AuditEntry struct {
+	AceIndex       int           `json:"aceIndex"`
+	Principal      SID           `json:"principal"`
+	Rights         ACEAccessMask `json:"rights"`
+	Success        bool          `json:"success"`
+	Failure        bool          `json:"failure"`
+	Inherited      bool          `json:"inherited"`
+	ObjectType     *GUID         `json:"objectType,omitempty"`
+	ObjectTypeName string        `json:"objectTypeName,omitempty"`
+}
+
+// AuditCoverage builds an AuditCoverageReport, resolving object types
+// with the built-in GUID tables
+func AuditCoverage(objects []DirectoryObject) AuditCoverageReport {
+	var builtin *GUIDResolver
+	return builtin.AuditCoverage(objects)
+}
+
+// AuditCoverage builds an AuditCoverageReport from objects read with
+// their SACL, e.g. with an LDAP_SERVER_SD_FLAGS control value of 0xF by
+// an account holding SeSecurityPrivilege. Inherit-only ACEs and ACEs
+// whose InheritedObjectType names neither the object's class nor one of
+// its superclasses are not listed. ACEs are kept when the class is
+// unknown to the loaded schema and the built-in table
+func (r *GUIDResolver) AuditCoverage(objects []DirectoryObject) AuditCoverageReport {
+	report := AuditCoverageReport{Objects: []ObjectAuditCoverage{}}
+
+	for _, object := range objects {
+		class := strings.ToLower(object.Class())
+		coverage := ObjectAuditCoverage{
+			DN:          object.DN,
+			ObjectClass: object.Class(),
+			Audits:      []AuditEntry{},
+		}
+
+		var covered uint32
+		for idx, ace := range object.SecurityDescriptor.SACL.Aces {
+			if !isAuditACE(ace) || ace.Header.Flags&ACEHeaderFlagsInheritOnlyAce != 0 {
+				continue
+			}
+			if !r.inheritedObjectTypeMatches(ace, class) {
+				continue
+			}
+
+			entry := AuditEntry{
+				AceIndex:  idx,
+				Principal: ace.ObjectAce.GetPrincipal(),
+				Rights:    ace.AccessMask,
+				Success:   ace.Header.Flags&ACEHeaderFlagsSuccessfulAccessAceFlag != 0,
+				Failure:   ace.Header.Flags&ACEHeaderFlagsFailedAccessAceFlag != 0,
+				Inherited: ace.Header.Flags&ACEHeaderFlagsInheritedAce != 0,
+			}
+			aa, isObject := ace.ObjectAce.(AdvancedAce)
+			if isObject && aa.Flags&ACEInheritanceFlagsObjectTypePresent != 0 {
+				objectType := aa.ObjectType
+				entry.ObjectType = &objectType
+				entry.ObjectTypeName = r.Resolve(objectType)
+			}
+			coverage.Audits = append(coverage.Audits, entry)
+
+			if entry.Success && entry.ObjectType == nil && AuditCoveragePrincipals[entry.Principal.String()] {
+				covered |= ADGenericMapping.Map(ace.AccessMask.Raw())
+			}
+		}
+
+		coverage.Missing = NewACEAccessMask(CriticalAuditRights &^ covered)
+		report.Objects = append(report.Objects, coverage)
+	}
+
+	return report
+}
+
+// Uncovered returns the objects missing audit ACEs for some of the
+// CriticalAuditRights
+func (r AuditCoverageReport) Uncovered() []ObjectAuditCoverage {
+	uncovered := []ObjectAuditCoverage{}
+	for _, object := range r.Objects {
+		if object.Missing.Raw() != 0 {
+			uncovered = append(uncovered, object)
+		}
+	}
+	return uncovered
+}
+
+// WriteJSON writes the report as a JSON document
+func (r AuditCoverageReport) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// WriteCSV writes the report with one row per audit ACE. Objects
+// without any audit ACE get a single row with empty audit columns
+func (r AuditCoverageReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"dn", "objectClass", "principal", "principalName", "rights",
+		"success", "failure", "inherited", "objectType", "objectTypeName", "missing",
+	})
+
+	for _, object := range r.Objects {
+		missing := strings.Join(object.Missing.StringSlice(), " ")
+		if len(object.Audits) == 0 {
+			cw.Write([]string{object.DN, object.ObjectClass, "", "", "", "", "", "", "", "", missing})
+			continue
+		}
+
+		for _, entry := range object.Audits {
+			objectType := ""
+			if entry.ObjectType != nil {
+				objectType = entry.ObjectType.String()
+			}
+			cw.Write([]string{
+				object.DN,
+				object.ObjectClass,
+				entry.Principal.String(),
+				entry.Principal.Resolve(),
+				strings.Join(entry.Rights.StringSlice(), " "),
+				strconv.FormatBool(entry.Success),
+				strconv.FormatBool(entry.Failure),
+				strconv.FormatBool(entry.Inherited),
+				objectType,
+				entry.ObjectTypeName,
+				missing,
+			})
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
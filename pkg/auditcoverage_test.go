@@ -0,0 +1,102 @@
+package winacl_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditCoverage(t *testing.T) {
+
+	r := require.New(t)
+
+	everyone, _ := winacl.ParseSID("S-1-1-0")
+	authUsers, _ := winacl.ParseSID("S-1-5-11")
+	personalInfo, _ := winacl.ParseGUID("77b5b886-944a-11d1-aebd-0000f80367c1")
+	success := winacl.ACEHeaderFlags(winacl.ACEHeaderFlagsSuccessfulAccessAceFlag)
+
+	domain, err := winacl.NewDescriptor().
+		Audit(everyone, winacl.ADSRightDSWriteProp|winacl.AccessMaskWriteDACL|winacl.AccessMaskWriteOwner, success).
+		Build()
+	r.NoError(err)
+
+	user, err := winacl.NewDescriptor().
+		Audit(authUsers, winacl.AccessMaskGenericWrite, success, winacl.ACEHeaderFlagsFailedAccessAceFlag).
+		AuditObject(everyone, winacl.ADSRightDSWriteProp, personalInfo, winacl.GUID{}, success).
+		Audit(everyone, winacl.AccessMaskGenericAll, success, winacl.ACEHeaderFlagsInheritOnlyAce).
+		Build()
+	r.NoError(err)
+
+	report := winacl.AuditCoverage([]winacl.DirectoryObject{
+		{DN: "DC=corp,DC=local", ObjectClass: []string{"top", "domainDNS"}, SecurityDescriptor: domain},
+		{DN: "CN=jdoe,CN=Users,DC=corp,DC=local", ObjectClass: []string{"top", "user"}, SecurityDescriptor: user},
+		{DN: "CN=unaudited,DC=corp,DC=local", ObjectClass: []string{"top", "user"}, SecurityDescriptor: newTestSD()},
+	})
+
+	t.Run("Lists audit ACEs and missing rights per object", func(t *testing.T) {
+		r.Len(report.Objects, 3)
+		r.Zero(report.Objects[0].Missing.Raw())
+
+		jdoe := report.Objects[1]
+		r.Equal("user", jdoe.ObjectClass)
+		r.Len(jdoe.Audits, 2)
+		r.True(jdoe.Audits[0].Failure)
+		r.Equal("Personal-Information", jdoe.Audits[1].ObjectTypeName)
+		r.Equal(uint32(winacl.AccessMaskWriteDACL|winacl.AccessMaskWriteOwner), jdoe.Missing.Raw())
+
+		r.Empty(report.Objects[2].Audits)
+		r.Equal(uint32(winacl.CriticalAuditRights), report.Objects[2].Missing.Raw())
+
+		uncovered := report.Uncovered()
+		r.Len(uncovered, 2)
+		r.Equal("CN=jdoe,CN=Users,DC=corp,DC=local", uncovered[0].DN)
+	})
+
+	t.Run("Counts audit ACEs scoped to a superclass", func(t *testing.T) {
+		userClass, _ := winacl.ParseGUID("bf967aba-0de6-11d0-a285-00aa003049e2")
+		scoped, err := winacl.NewDescriptor().
+			AuditObject(everyone, winacl.AccessMaskGenericAll, winacl.GUID{}, userClass, success).
+			Build()
+		r.NoError(err)
+
+		report := winacl.AuditCoverage([]winacl.DirectoryObject{
+			{DN: "CN=jsmith,CN=Users,DC=corp,DC=local", ObjectClass: []string{"top", "person", "organizationalPerson", "user", "inetOrgPerson"}, SecurityDescriptor: scoped},
+			{DN: "CN=Sales,CN=Users,DC=corp,DC=local", ObjectClass: []string{"top", "group"}, SecurityDescriptor: scoped},
+		})
+		r.Len(report.Objects[0].Audits, 1)
+		r.Zero(report.Objects[0].Missing.Raw())
+		r.Empty(report.Objects[1].Audits)
+		r.Equal(uint32(winacl.CriticalAuditRights), report.Objects[1].Missing.Raw())
+	})
+
+	t.Run("Exports to CSV", func(t *testing.T) {
+		buf := bytes.Buffer{}
+		r.NoError(report.WriteCSV(&buf))
+
+		rows, err := csv.NewReader(&buf).ReadAll()
+		r.NoError(err)
+		r.Len(rows, 5)
+		r.Equal("dn", rows[0][0])
+		r.Equal([]string{
+			"CN=jdoe,CN=Users,DC=corp,DC=local", "user", "S-1-5-11", "Authenticated Users", "GENERIC_WRITE",
+			"true", "true", "false", "", "", "WRITE_DACL WRITE_OWNER",
+		}, rows[2])
+		r.Equal("CN=unaudited,DC=corp,DC=local", rows[4][0])
+		r.Empty(rows[4][2])
+	})
+
+	t.Run("Exports to JSON", func(t *testing.T) {
+		buf := bytes.Buffer{}
+		r.NoError(report.WriteJSON(&buf))
+
+		var decoded winacl.AuditCoverageReport
+		r.NoError(json.Unmarshal(buf.Bytes(), &decoded))
+		r.Equal(report.Objects[1].Audits[1].ObjectType, decoded.Objects[1].Audits[1].ObjectType)
+		r.Equal(report.Objects[1].Missing, decoded.Objects[1].Missing)
+	})
+
+}
@@ -0,0 +1,190 @@
+package winacl
+
+// DescriptorBuilder assembles an NtSecurityDescriptor ACE by ACE, e.g.
+//
+//	ntsd, err := NewDescriptor().
+//		Owner(domainAdmins).
+//		Group(domainAdmins).
+//		Allow(authenticatedUsers, AccessMaskReadControl).
+//		AllowObject(everyone, ADSRightDSControlAccess, userChangePassword, GUID{}).
+//		Protected().
+//		Build()
+//
+// ACEs are kept in the order they are added. Object ACEs are built
+// with ObjectType and InheritedObjectType only when those are not
+// the null GUID
+type DescriptorBuilder struct {
+	ntsd    NtSecurityDescriptor
+	dacl    []ACE
+	sacl    []ACE
+	hasDACL bool
+	hasSACL bool
+}
+
+// NewDescriptor is a constructor for an empty DescriptorBuilder
+func NewDescriptor() *DescriptorBuilder {
+	return &DescriptorBuilder{
+		ntsd: NtSecurityDescriptor{
+			Header: NtSecurityDescriptorHeader{Revision: 1},
+		},
+	}
+}
+
+// Owner sets the descriptor's owner
+func (b *DescriptorBuilder) Owner(sid SID) *DescriptorBuilder {
+	b.ntsd.Owner = sid
+	return b
+}
+
+// Group sets the descriptor's primary group
+func (b *DescriptorBuilder) Group(sid SID) *DescriptorBuilder {
+	b.ntsd.Group = sid
+	return b
+}
+
+// Control sets additional control bits on the descriptor's header
+func (b *DescriptorBuilder) Control(control ControlFlags) *DescriptorBuilder {
+	b.ntsd.Header.Control |= control
+	return b
+}
+
+// Protected blocks the DACL from inheriting ACEs from parent objects
+func (b *DescriptorBuilder) Protected() *DescriptorBuilder {
+	return b.Control(DACLProtected)
+}
+
+// EmptyDACL includes a DACL in the descriptor even if no ACE is added
+// to it, denying all access
+func (b *DescriptorBuilder) EmptyDACL() *DescriptorBuilder {
+	b.hasDACL = true
+	return b
+}
+
+// Allow appends an ACCESS_ALLOWED ACE to the DACL
+func (b *DescriptorBuilder) Allow(sid SID, mask uint32, flags ...ACEHeaderFlags) *DescriptorBuilder {
+	return b.addDACL(BuildBasicAce(AceTypeAccessAllowed, joinHeaderFlags(flags), mask, sid))
+}
+
+// AllowObject appends an ACCESS_ALLOWED_OBJECT ACE to the DACL
+func (b *DescriptorBuilder) AllowObject(sid SID, mask uint32, objectType GUID, inheritedObjectType GUID, flags ...ACEHeaderFlags) *DescriptorBuilder {
+	return b.addDACL(BuildObjectAce(AceTypeAccessAllowedObject, joinHeaderFlags(flags), mask, sid, objectType, inheritedObjectType))
+}
+
+// Deny appends an ACCESS_DENIED ACE to the DACL
+func (b *DescriptorBuilder) Deny(sid SID, mask uint32, flags ...ACEHeaderFlags) *DescriptorBuilder {
+	return b.addDACL(BuildBasicAce(AceTypeAccessDenied, joinHeaderFlags(flags), mask, sid))
+}
+
+// DenyObject appends an ACCESS_DENIED_OBJECT ACE to the DACL
+func (b *DescriptorBuilder) DenyObject(sid SID, mask uint32, objectType GUID, inheritedObjectType GUID, flags ...ACEHeaderFlags) *DescriptorBuilder {
+	return b.addDACL(BuildObjectAce(AceTypeAccessDeniedObject, joinHeaderFlags(flags), mask, sid, objectType, inheritedObjectType))
+}
+
+// Audit appends a SYSTEM_AUDIT ACE to the SACL. Flags should include
+// ACEHeaderFlagsSuccessfulAccessAceFlag, ACEHeaderFlagsFailedAccessAceFlag
+// or both, as an audit ACE without either never generates events
+func (b *DescriptorBuilder) Audit(sid SID, mask uint32, flags ...ACEHeaderFlags) *DescriptorBuilder {
+	return b.addSACL(BuildBasicAce(AceTypeSystemAudit, joinHeaderFlags(flags), mask, sid))
+}
+
+// AuditObject appends a SYSTEM_AUDIT_OBJECT ACE to the SACL
+func (b *DescriptorBuilder) AuditObject(sid SID, mask uint32, objectType GUID, inheritedObjectType GUID, flags ...ACEHeaderFlags) *DescriptorBuilder {
+	return b.addSACL(BuildObjectAce(AceTypeSystemAuditObject, joinHeaderFlags(flags), mask, sid, objectType, inheritedObjectType))
+}
+
+// Build returns the assembled descriptor, with ACL headers, offsets
+// and control bits matching what ToBuffer will write
+func (b *DescriptorBuilder) Build() (NtSecurityDescriptor, error) {
+	ntsd := b.ntsd
+	ntsd.Header.Control |= SelfRelative
+
+	var err error
+	if b.hasSACL {
+		ntsd.SACL, err = newBuiltACL(b.sacl)
+		if err != nil {
+			return ntsd, err
+		}
+	}
+	if b.hasDACL {
+		dacl, err := newBuiltACL(b.dacl)
+		if err != nil {
+			return ntsd, err
+		}
+		ntsd.DACL = &dacl
+	}
+
+	// Round-trip through the binary encoding for the header offsets
+	buf, err := ntsd.ToBuffer()
+	if err != nil {
+		return ntsd, err
+	}
+	ntsd.Header, err = NewNTSDHeader(&buf)
+	return ntsd, err
+}
+
+func (b *DescriptorBuilder) addDACL(ace ACE) *DescriptorBuilder {
+	b.dacl = append(b.dacl, ace)
+	b.hasDACL = true
+	return b
+}
+
+func (b *DescriptorBuilder) addSACL(ace ACE) *DescriptorBuilder {
+	b.sacl = append(b.sacl, ace)
+	b.hasSACL = true
+	return b
+}
+
+// BuildBasicAce returns an ACE of a non-object type, such as
+// ACCESS_ALLOWED, with its header size set
+func BuildBasicAce(aceType AceType, flags ACEHeaderFlags, mask uint32, sid SID) ACE {
+	ace := ACE{
+		Header:     ACEHeader{Type: aceType, Flags: flags},
+		AccessMask: NewACEAccessMask(mask),
+		ObjectAce:  BasicAce{SecurityIdentifier: sid},
+	}
+	ace.Header.Size = uint16(ace.Size())
+	return ace
+}
+
+// BuildObjectAce returns an ACE of an object type, such as
+// ACCESS_ALLOWED_OBJECT, with its header size and object flags set.
+// A null objectType or inheritedObjectType is left out of the ACE
+func BuildObjectAce(aceType AceType, flags ACEHeaderFlags, mask uint32, sid SID, objectType GUID, inheritedObjectType GUID) ACE {
+	aa := AdvancedAce{SecurityIdentifier: sid}
+	if !objectType.IsNull() {
+		aa.Flags |= ACEInheritanceFlagsObjectTypePresent
+		aa.ObjectType = objectType
+	}
+	if !inheritedObjectType.IsNull() {
+		aa.Flags |= ACEInheritanceFlagsInheritedObjectTypePresent
+		aa.InheritedObjectType = inheritedObjectType
+	}
+
+	ace := ACE{
+		Header:     ACEHeader{Type: aceType, Flags: flags},
+		AccessMask: NewACEAccessMask(mask),
+		ObjectAce:  aa,
+	}
+	ace.Header.Size = uint16(ace.Size())
+	return ace
+}
+
+// newBuiltACL returns an ACL holding aces, with its header computed
+func newBuiltACL(aces []ACE) (ACL, error) {
+	acl := ACL{Aces: aces}
+	if acl.Aces == nil {
+		acl.Aces = []ACE{}
+	}
+
+	var err error
+	acl.Header, err = acl.computeHeader()
+	return acl, err
+}
+
+func joinHeaderFlags(flags []ACEHeaderFlags) ACEHeaderFlags {
+	var joined ACEHeaderFlags
+	for _, flag := range flags {
+		joined |= flag
+	}
+	return joined
+}
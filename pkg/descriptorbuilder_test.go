@@ -0,0 +1,94 @@
+package winacl_test
+
+import (
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescriptorBuilder(t *testing.T) {
+
+	r := require.New(t)
+
+	domainAdmins, _ := winacl.ParseSID("S-1-5-21-2333832797-2102143736-1942374753-512")
+	authUsers, _ := winacl.ParseSID("S-1-5-11")
+	everyone, _ := winacl.ParseSID("S-1-1-0")
+	userChangePassword, _ := winacl.ParseGUID("ab721a53-1e2f-11d0-9819-00aa0040529b")
+	user, _ := winacl.ParseGUID("bf967aba-0de6-11d0-a285-00aa003049e2")
+
+	t.Run("Builds a descriptor that serializes and parses back", func(t *testing.T) {
+		ntsd, err := winacl.NewDescriptor().
+			Owner(domainAdmins).
+			Group(domainAdmins).
+			Deny(everyone, winacl.AccessMaskDelete).
+			Allow(authUsers, winacl.AccessMaskReadControl).
+			AllowObject(everyone, winacl.ADSRightDSControlAccess, userChangePassword, winacl.GUID{}).
+			AllowObject(authUsers, winacl.ADSRightDSReadProp, winacl.GUID{}, user, winacl.ACEHeaderFlagsContainerInheritAce, winacl.ACEHeaderFlagsInheritOnlyAce).
+			Audit(everyone, winacl.AccessMaskWriteDACL, winacl.ACEHeaderFlagsFailedAccessAceFlag).
+			Protected().
+			Build()
+		r.NoError(err)
+
+		r.Equal(
+			"O:S-1-5-21-2333832797-2102143736-1942374753-512G:S-1-5-21-2333832797-2102143736-1942374753-512D:P"+
+				"(D;;SD;;;WD)(A;;RC;;;AU)(OA;;CR;ab721a53-1e2f-11d0-9819-00aa0040529b;;WD)"+
+				"(OA;CIIO;RP;;bf967aba-0de6-11d0-a285-00aa003049e2;AU)S:(AU;FA;WD;;;WD)",
+			ntsd.ToSDDL(),
+		)
+
+		r.Equal(uint8(winacl.ACLRevisionDS), ntsd.DACL.Header.Revision)
+		r.Equal(uint16(4), ntsd.DACL.Header.AceCount)
+		r.Equal(uint16(8+20+20+40+40), ntsd.DACL.Header.Size)
+		r.Equal(uint8(winacl.ACLRevision), ntsd.SACL.Header.Revision)
+		r.Equal(winacl.SelfRelative|winacl.DACLPresent|winacl.SACLPresent|winacl.DACLProtected, ntsd.Header.Control)
+
+		buf, err := ntsd.ToBuffer()
+		r.NoError(err)
+		parsed, err := winacl.NewNtSecurityDescriptor(buf.Bytes())
+		r.NoError(err)
+		r.Equal(ntsd, parsed)
+	})
+
+	t.Run("Leaves out ACLs without ACEs unless requested", func(t *testing.T) {
+		ntsd, err := winacl.NewDescriptor().Owner(domainAdmins).Build()
+		r.NoError(err)
+		r.Zero(ntsd.Header.OffsetDacl)
+		r.Zero(ntsd.Header.OffsetSacl)
+
+		ntsd, err = winacl.NewDescriptor().EmptyDACL().Build()
+		r.NoError(err)
+		r.Equal(uint32(20), ntsd.Header.OffsetDacl)
+		r.Empty(ntsd.DACL.Aces)
+		r.Equal(uint16(8), ntsd.DACL.Header.Size)
+	})
+
+	t.Run("Rejects ACLs over 64KB", func(t *testing.T) {
+		builder := winacl.NewDescriptor()
+		for i := 0; i < 3000; i++ {
+			builder.Allow(domainAdmins, winacl.AccessMaskReadControl)
+		}
+		_, err := builder.Build()
+		r.Error(err)
+		r.IsType(winacl.ACLTooLargeError{}, err)
+	})
+
+}
+
+func TestBuildAce(t *testing.T) {
+
+	r := require.New(t)
+	everyone, _ := winacl.ParseSID("S-1-1-0")
+
+	t.Run("Sets the header size and object flags", func(t *testing.T) {
+		ace := winacl.BuildObjectAce(winacl.AceTypeAccessAllowedObject, 0, winacl.ADSRightDSReadProp, everyone, winacl.GUID{}, winacl.GUID{})
+		r.Equal(uint16(24), ace.Header.Size)
+		r.Equal(winacl.ACEInheritanceFlags(0), ace.ObjectAce.(winacl.AdvancedAce).Flags)
+
+		ace = winacl.BuildBasicAce(winacl.AceTypeAccessAllowed, 0, 0x1F01FF, everyone)
+		r.Equal(uint16(20), ace.Header.Size)
+		r.Equal(winacl.NewACEAccessMask(0x1F01FF), ace.AccessMask)
+		r.Equal(uint32(0x1F01FF), ace.AccessMask.Raw())
+	})
+
+}
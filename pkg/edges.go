@@ -0,0 +1,378 @@
+package winacl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EdgeKind is the kind of attack path an ACE grants over an
+// object, named as in BloodHound
+type EdgeKind string
+
+const (
+	EdgeGenericAll          EdgeKind = "GenericAll"
+	EdgeGenericWrite        EdgeKind = "GenericWrite"
+	EdgeWriteOwner          EdgeKind = "WriteOwner"
+	EdgeWriteDacl           EdgeKind = "WriteDacl"
+	EdgeAllExtendedRights   EdgeKind = "AllExtendedRights"
+	EdgeForceChangePassword EdgeKind = "ForceChangePassword"
+	EdgeAddMember           EdgeKind = "AddMember"
+	EdgeAddSelf             EdgeKind = "AddSelf"
+	EdgeReadLAPSPassword    EdgeKind = "ReadLAPSPassword"
+	EdgeReadGMSAPassword    EdgeKind = "ReadGMSAPassword"
+	EdgeDCSync              EdgeKind = "DCSync"
+)
+
+// lDAPDisplayNames of the object classes edges are computed for
+const (
+	ObjectClassUser                 = "user"
+	ObjectClassComputer             = "computer"
+	ObjectClassGroup                = "group"
+	ObjectClassDomain               = "domainDNS"
+	ObjectClassOrganizationalUnit   = "organizationalUnit"
+	ObjectClassContainer            = "container"
+	ObjectClassGroupPolicyContainer = "groupPolicyContainer"
+	ObjectClassGMSA                 = "msDS-GroupManagedServiceAccount"
+)
+
+// schemaClass is the schemaIDGUID of a class and the lowercased
+// lDAPDisplayName of its superclass (subClassOf)
+type schemaClass struct {
+	guid       string
+	superclass string
+}
+
+// builtinClasses describes the default classes ACEs are most often
+// scoped to, keyed by lowercased lDAPDisplayName. Classes loaded with
+// their subClassOf from a schema export take precedence
+var builtinClasses = map[string]schemaClass{
+	"top":                             {"bf967ab7-0de6-11d0-a285-00aa003049e2", "top"},
+	"person":                          {"bf967aa7-0de6-11d0-a285-00aa003049e2", "top"},
+	"organizationalperson":            {"bf967aa4-0de6-11d0-a285-00aa003049e2", "person"},
+	"user":                            {"bf967aba-0de6-11d0-a285-00aa003049e2", "organizationalperson"},
+	"inetorgperson":                   {"4828cc14-1437-45bc-9b07-ad6f015e5f28", "user"},
+	"contact":                         {"5cb41ed0-0e4c-11d0-a286-00aa003049e2", "organizationalperson"},
+	"computer":                        {"bf967a86-0de6-11d0-a285-00aa003049e2", "user"},
+	"msds-managedserviceaccount":      {"ce206244-5827-4a86-ba1c-1c0c386c1b64", "computer"},
+	"msds-groupmanagedserviceaccount": {"7b8b558a-93a5-4af7-adca-c017e67f1057", "computer"},
+	"group":                           {"bf967a9c-0de6-11d0-a285-00aa003049e2", "top"},
+	"foreignsecurityprincipal":        {"89e31c12-8530-11d0-afda-00c04fd930c9", "top"},
+	"domain":                          {"19195a5a-6da0-11d0-afd3-00c04fd930c9", "top"},
+	"domaindns":                       {"19195a5b-6da0-11d0-afd3-00c04fd930c9", "domain"},
+	"builtindomain":                   {"bf967a81-0de6-11d0-a285-00aa003049e2", "top"},
+	"organizationalunit":              {"bf967aa5-0de6-11d0-a285-00aa003049e2", "top"},
+	"container":                       {"bf967a8b-0de6-11d0-a285-00aa003049e2", "top"},
+	"grouppolicycontainer":            {"f30e3bc2-9ff0-11d1-b603-0000f80367c1", "container"},
+}
+
+// Object types checked when computing edges
+const (
+	guidForceChangePassword = "00299570-246d-11d0-a768-00aa006e0529"
+	guidGetChanges          = "1131f6aa-9c07-11d1-f79f-00c04fc2dcd2"
+	guidGetChangesAll       = "1131f6ad-9c07-11d1-f79f-00c04fc2dcd2"
+	guidMember              = "bf9679c0-0de6-11d0-a285-00aa003049e2"
+)
+
+// lapsAttributeNames are resolved through the schema, as the
+// schemaIDGUID of the legacy LAPS attribute differs between forests
+var lapsAttributeNames = []string{"ms-Mcs-AdmPwd", "msLAPS-Password", "msLAPS-EncryptedPassword"}
+
+const (
+	// adsRightsGenericAll is ActiveDirectoryRights.GenericAll, the full
+	// set of standard and directory service rights
+	adsRightsGenericAll = 0x000F01FF
+	// adsRightsGenericWrite is ActiveDirectoryRights.GenericWrite
+	adsRightsGenericWrite = AccessMaskReadControl | ADSRightDSWriteProp | ADSRightDSSelf
+)
+
+// FilteredEdgeSIDs are principals, mostly logon-type and service
+// SIDs, that never produce edges
+var FilteredEdgeSIDs = map[string]bool{
+	"S-1-0":    true,
+	"S-1-0-0":  true,
+	"S-1-2":    true,
+	"S-1-2-0":  true,
+	"S-1-2-1":  true,
+	"S-1-5-2":  true,
+	"S-1-5-3":  true,
+	"S-1-5-4":  true,
+	"S-1-5-6":  true,
+	"S-1-5-7":  true,
+	"S-1-5-18": true,
+	"S-1-5-19": true,
+	"S-1-5-20": true,
+}
+
+var filteredEdgeSIDPrefixes = []string{"S-1-5-80-", "S-1-5-82-", "S-1-5-90-", "S-1-5-96-"}
+
+// Edge is an attack path a principal holds over an object, along
+// with the DACL entry that grants it. AceIndex is -1, and Ace empty,
+// for the edge Everyone holds through a NULL DACL
+type Edge struct {
+	Kind      EdgeKind
+	Principal SID
+	Inherited bool
+	AceIndex  int
+	Ace       ACE
+}
+
+// String returns an human-readable representation of an Edge
+func (e Edge) String() string {
+	return fmt.Sprintf("%s -[%s]->", e.Principal.Resolve(), e.Kind)
+}
+
+// Edges interprets the DACL of an object of the given class (its
+// lDAPDisplayName, e.g. "user") the way SharpHound does, using the
+// built-in GUID tables. Deny ACEs are not taken into account
+func Edges(ntsd NtSecurityDescriptor, objectClass string) []Edge {
+	var builtin *GUIDResolver
+	return builtin.Edges(ntsd, objectClass)
+}
+
+// Edges interprets the DACL of an object of the given class (its
+// lDAPDisplayName, e.g. "user") the way SharpHound does. A DCSync edge
+// is emitted, with the ACE completing the pair, once a principal holds
+// both DS-Replication-Get-Changes and -Get-Changes-All. Inherit-only
+// ACEs and ACEs whose InheritedObjectType names neither the class nor
+// one of its superclasses are skipped, as they do not apply to the
+// object itself. Classes unknown to the loaded schema and the built-in
+// table keep every ACE. Deny ACEs are not taken into account. A NULL
+// DACL yields a GenericAll edge for Everyone
+func (r *GUIDResolver) Edges(ntsd NtSecurityDescriptor, objectClass string) []Edge {
+	if ntsd.DACL == nil {
+		return []Edge{nullDACLEdge(EdgeGenericAll)}
+	}
+
+	class := strings.ToLower(objectClass)
+	lapsGUIDs := r.lapsGUIDs()
+
+	edges := []Edge{}
+	getChanges := make(map[string]bool)
+	getChangesAll := make(map[string]bool)
+
+	for idx, ace := range ntsd.daclAces() {
+		if !r.appliesToObject(ace, class) {
+			continue
+		}
+
+		principal := ace.ObjectAce.GetPrincipal()
+		if isFilteredEdgeSID(principal) {
+			continue
+		}
+
+		emit := func(kind EdgeKind) {
+			edges = append(edges, Edge{
+				Kind:      kind,
+				Principal: principal,
+				Inherited: ace.Header.Flags&ACEHeaderFlagsInheritedAce != 0,
+				AceIndex:  idx,
+				Ace:       ace,
+			})
+		}
+		emitDCSync := func() {
+			if sid := principal.String(); getChanges[sid] && getChangesAll[sid] {
+				emit(EdgeDCSync)
+				delete(getChanges, sid)
+				delete(getChangesAll, sid)
+			}
+		}
+
+		rights := ace.AccessMask.Raw()
+		objectType := ""
+		if aa, ok := ace.ObjectAce.(AdvancedAce); ok && aa.Flags&ACEInheritanceFlagsObjectTypePresent != 0 {
+			objectType = aa.ObjectType.String()
+		}
+		allTypes := objectType == ""
+
+		if rights&AccessMaskGenericAll != 0 || rights&adsRightsGenericAll == adsRightsGenericAll {
+			if allTypes {
+				emit(EdgeGenericAll)
+			} else if class == "computer" && lapsGUIDs[objectType] {
+				emit(EdgeReadLAPSPassword)
+			}
+			continue
+		}
+
+		if rights&AccessMaskWriteDACL != 0 {
+			emit(EdgeWriteDacl)
+		}
+		if rights&AccessMaskWriteOwner != 0 {
+			emit(EdgeWriteOwner)
+		}
+
+		if rights&ADSRightDSControlAccess != 0 {
+			switch class {
+			case "domaindns":
+				switch objectType {
+				case "":
+					emit(EdgeAllExtendedRights)
+				case guidGetChanges:
+					getChanges[principal.String()] = true
+					emitDCSync()
+				case guidGetChangesAll:
+					getChangesAll[principal.String()] = true
+					emitDCSync()
+				}
+			case "user":
+				if allTypes {
+					emit(EdgeAllExtendedRights)
+				} else if objectType == guidForceChangePassword {
+					emit(EdgeForceChangePassword)
+				}
+			case "computer", "msds-groupmanagedserviceaccount":
+				if allTypes {
+					emit(EdgeAllExtendedRights)
+				} else if lapsGUIDs[objectType] {
+					emit(EdgeReadLAPSPassword)
+				}
+			}
+		}
+
+		genericWrite := rights&AccessMaskGenericWrite != 0 || rights&adsRightsGenericWrite == adsRightsGenericWrite
+		if genericWrite || rights&ADSRightDSWriteProp != 0 {
+			switch class {
+			case "user", "group", "computer", "grouppolicycontainer", "organizationalunit", "domaindns", "msds-groupmanagedserviceaccount":
+				if allTypes {
+					emit(EdgeGenericWrite)
+				}
+			}
+			if class == "group" && objectType == guidMember {
+				emit(EdgeAddMember)
+			}
+		}
+
+		if rights&ADSRightDSSelf != 0 && !genericWrite && rights&ADSRightDSWriteProp == 0 {
+			if class == "group" && objectType == guidMember {
+				emit(EdgeAddSelf)
+			}
+		}
+	}
+
+	return edges
+}
+
+// ReadGMSAPasswordEdges returns a ReadGMSAPassword edge for every
+// principal allowed by the descriptor stored in a group managed service
+// account's msDS-GroupMSAMembership attribute, or for Everyone when its
+// DACL is NULL
+func ReadGMSAPasswordEdges(membership NtSecurityDescriptor) []Edge {
+	if membership.DACL == nil {
+		return []Edge{nullDACLEdge(EdgeReadGMSAPassword)}
+	}
+
+	edges := []Edge{}
+	for idx, ace := range membership.daclAces() {
+		if ace.Header.Type != AceTypeAccessAllowed && ace.Header.Type != AceTypeAccessAllowedObject {
+			continue
+		}
+
+		principal := ace.ObjectAce.GetPrincipal()
+		if isFilteredEdgeSID(principal) {
+			continue
+		}
+
+		edges = append(edges, Edge{
+			Kind:      EdgeReadGMSAPassword,
+			Principal: principal,
+			Inherited: ace.Header.Flags&ACEHeaderFlagsInheritedAce != 0,
+			AceIndex:  idx,
+			Ace:       ace,
+		})
+	}
+	return edges
+}
+
+// nullDACLEdge is the edge Everyone holds over an object whose DACL is
+// NULL
+func nullDACLEdge(kind EdgeKind) Edge {
+	everyone, _ := ParseSID(everyoneSID)
+	return Edge{Kind: kind, Principal: everyone, AceIndex: -1}
+}
+
+// appliesToObject reports whether an allow ACE takes effect on the object
+// it is attached to, given the object's lowercased class
+func (r *GUIDResolver) appliesToObject(ace ACE, class string) bool {
+	if ace.Header.Type != AceTypeAccessAllowed && ace.Header.Type != AceTypeAccessAllowedObject {
+		return false
+	}
+	if ace.Header.Flags&ACEHeaderFlagsInheritOnlyAce != 0 {
+		return false
+	}
+
+	return r.inheritedObjectTypeMatches(ace, class)
+}
+
+// inheritedObjectTypeMatches reports whether an ACE's InheritedObjectType,
+// if any, names the lowercased class or one of its superclasses. When
+// the class or one of its superclasses is unknown the ACE is assumed to
+// apply, so that it is reported rather than silently dropped
+func (r *GUIDResolver) inheritedObjectTypeMatches(ace ACE, class string) bool {
+	aa, ok := ace.ObjectAce.(AdvancedAce)
+	if !ok || aa.Flags&ACEInheritanceFlagsInheritedObjectTypePresent == 0 {
+		return true
+	}
+
+	classGUIDs, known := r.classGUIDs(class)
+	if !known {
+		return true
+	}
+
+	inherited := aa.InheritedObjectType.String()
+	for _, classGUID := range classGUIDs {
+		if inherited == classGUID {
+			return true
+		}
+	}
+	return false
+}
+
+// classGUIDs returns the schemaIDGUIDs of a lowercased class and of its
+// superclasses. known is false when any of them is missing from both the
+// loaded schema and the built-in table
+func (r *GUIDResolver) classGUIDs(class string) (guids []string, known bool) {
+	seen := make(map[string]bool)
+	for !seen[class] {
+		seen[class] = true
+
+		sc, found := r.schemaClass(class)
+		if !found {
+			return nil, false
+		}
+		guids = append(guids, sc.guid)
+		class = sc.superclass
+	}
+	return guids, true
+}
+
+func (r *GUIDResolver) schemaClass(class string) (schemaClass, bool) {
+	if r != nil {
+		if sc, found := r.classes[class]; found {
+			return sc, true
+		}
+	}
+	sc, found := builtinClasses[class]
+	return sc, found
+}
+
+func (r *GUIDResolver) lapsGUIDs() map[string]bool {
+	guids := make(map[string]bool)
+	for _, name := range lapsAttributeNames {
+		if info, err := r.LookupName(name); err == nil {
+			guids[info.GUID.String()] = true
+		}
+	}
+	return guids
+}
+
+func isFilteredEdgeSID(sid SID) bool {
+	sidStr := sid.String()
+	if FilteredEdgeSIDs[sidStr] {
+		return true
+	}
+	for _, prefix := range filteredEdgeSIDPrefixes {
+		if strings.HasPrefix(sidStr, prefix) {
+			return true
+		}
+	}
+	return false
+}
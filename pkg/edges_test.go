@@ -0,0 +1,148 @@
+package winacl_test
+
+import (
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func edgeKinds(edges []winacl.Edge, principal string) []winacl.EdgeKind {
+	kinds := []winacl.EdgeKind{}
+	for _, edge := range edges {
+		if edge.Principal.String() == principal {
+			kinds = append(kinds, edge.Kind)
+		}
+	}
+	return kinds
+}
+
+func TestEdges(t *testing.T) {
+
+	r := require.New(t)
+
+	t.Run("Extracts edges from a user's DACL", func(t *testing.T) {
+		edges := winacl.Edges(newTestSD(), winacl.ObjectClassUser)
+
+		domainAdmins := "S-1-5-21-2333832797-2102143736-1942374753-512"
+		r.Equal([]winacl.EdgeKind{winacl.EdgeGenericAll}, edgeKinds(edges, domainAdmins))
+
+		r.ElementsMatch([]winacl.EdgeKind{
+			winacl.EdgeWriteDacl,
+			winacl.EdgeWriteOwner,
+			winacl.EdgeAllExtendedRights,
+			winacl.EdgeGenericWrite,
+		}, edgeKinds(edges, "S-1-5-32-544"))
+
+		r.Empty(edgeKinds(edges, "S-1-5-18"))
+
+		for _, edge := range edgeKinds(edges, "S-1-5-21-2333832797-2102143736-1942374753-519") {
+			r.Equal(winacl.EdgeGenericAll, edge)
+		}
+	})
+
+	t.Run("Emits DCSync once both replication rights are held", func(t *testing.T) {
+		getChanges, err := winacl.ParseGUID("1131f6aa-9c07-11d1-f79f-00c04fc2dcd2")
+		r.NoError(err)
+		getChangesAll, err := winacl.ParseGUID("1131f6ad-9c07-11d1-f79f-00c04fc2dcd2")
+		r.NoError(err)
+
+		first, err := newTestObjectACE(winacl.ADSRightDSControlAccess, getChanges)
+		r.NoError(err)
+		second, err := newTestObjectACE(winacl.ADSRightDSControlAccess, getChangesAll)
+		r.NoError(err)
+
+		sd := winacl.NtSecurityDescriptor{DACL: &winacl.ACL{Aces: []winacl.ACE{first}}}
+		r.Empty(winacl.Edges(sd, winacl.ObjectClassDomain))
+
+		sd.DACL.Aces = append(sd.DACL.Aces, second)
+		edges := winacl.Edges(sd, winacl.ObjectClassDomain)
+		r.Len(edges, 1)
+		r.Equal(winacl.EdgeDCSync, edges[0].Kind)
+		r.Equal(1, edges[0].AceIndex)
+	})
+
+	t.Run("Distinguishes AddMember from AddSelf", func(t *testing.T) {
+		member, err := winacl.ParseGUID("bf9679c0-0de6-11d0-a285-00aa003049e2")
+		r.NoError(err)
+
+		write, err := newTestObjectACE(winacl.ADSRightDSWriteProp, member)
+		r.NoError(err)
+		self, err := newTestObjectACE(winacl.ADSRightDSSelf, member)
+		r.NoError(err)
+
+		sd := winacl.NtSecurityDescriptor{DACL: &winacl.ACL{Aces: []winacl.ACE{write, self}}}
+		r.Equal(
+			[]winacl.EdgeKind{winacl.EdgeAddMember, winacl.EdgeAddSelf},
+			edgeKinds(winacl.Edges(sd, winacl.ObjectClassGroup), "S-1-1-0"),
+		)
+		r.Empty(winacl.Edges(sd, winacl.ObjectClassUser))
+	})
+
+	t.Run("Resolves LAPS attributes through a loaded schema", func(t *testing.T) {
+		resolver, err := newTestGUIDResolver()
+		r.NoError(err)
+		laps, err := resolver.LookupName("ms-Mcs-AdmPwd")
+		r.NoError(err)
+
+		ace, err := newTestObjectACE(winacl.ADSRightDSControlAccess, laps.GUID)
+		r.NoError(err)
+
+		sd := winacl.NtSecurityDescriptor{DACL: &winacl.ACL{Aces: []winacl.ACE{ace}}}
+		r.Empty(winacl.Edges(sd, winacl.ObjectClassComputer))
+		r.Equal(
+			[]winacl.EdgeKind{winacl.EdgeReadLAPSPassword},
+			edgeKinds(resolver.Edges(sd, winacl.ObjectClassComputer), "S-1-1-0"),
+		)
+	})
+
+	t.Run("Lists gMSA password readers", func(t *testing.T) {
+		edges := winacl.ReadGMSAPasswordEdges(newTestSD())
+		r.NotEmpty(edges)
+		for _, edge := range edges {
+			r.Equal(winacl.EdgeReadGMSAPassword, edge.Kind)
+		}
+	})
+
+	t.Run("Matches InheritedObjectTypes against the class hierarchy", func(t *testing.T) {
+		everyone, err := winacl.ParseSID("S-1-1-0")
+		r.NoError(err)
+		scopedTo := func(class string) winacl.NtSecurityDescriptor {
+			classGUID, err := winacl.ParseGUID(class)
+			r.NoError(err)
+			ace := winacl.BuildObjectAce(winacl.AceTypeAccessAllowedObject, 0, 0xF01FF, everyone, winacl.GUID{}, classGUID)
+			return winacl.NtSecurityDescriptor{DACL: &winacl.ACL{Aces: []winacl.ACE{ace}}}
+		}
+		user := scopedTo("bf967aba-0de6-11d0-a285-00aa003049e2")
+		contact := scopedTo("5cb41ed0-0e4c-11d0-a286-00aa003049e2")
+		genericAll := []winacl.EdgeKind{winacl.EdgeGenericAll}
+
+		r.Equal(genericAll, edgeKinds(winacl.Edges(user, "inetOrgPerson"), "S-1-1-0"))
+		r.Equal(genericAll, edgeKinds(winacl.Edges(contact, "contact"), "S-1-1-0"))
+		r.Empty(winacl.Edges(user, winacl.ObjectClassGroup))
+		r.Empty(winacl.Edges(contact, winacl.ObjectClassUser))
+
+		// Classes missing from the schema are reported rather than dropped
+		r.Equal(genericAll, edgeKinds(winacl.Edges(user, "corpWidget"), "S-1-1-0"))
+
+		resolver, err := newTestGUIDResolver()
+		r.NoError(err)
+		widget := scopedTo("7e2f9a1c-3b4d-4f5e-a6b7-c8d9e0f1a2b3")
+		r.Equal(genericAll, edgeKinds(resolver.Edges(widget, "corpWidget"), "S-1-1-0"))
+		r.Empty(resolver.Edges(user, "corpWidget"))
+	})
+
+	t.Run("Grants Everyone GenericAll through a NULL DACL", func(t *testing.T) {
+		sd := newTestSD()
+		sd.DACL = nil
+
+		edges := winacl.Edges(sd, winacl.ObjectClassUser)
+		r.Len(edges, 1)
+		r.Equal(winacl.EdgeGenericAll, edges[0].Kind)
+		r.Equal("S-1-1-0", edges[0].Principal.String())
+		r.Equal(-1, edges[0].AceIndex)
+
+		r.Equal([]winacl.EdgeKind{winacl.EdgeReadGMSAPassword}, edgeKinds(winacl.ReadGMSAPasswordEdges(sd), "S-1-1-0"))
+	})
+
+}
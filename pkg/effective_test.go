@@ -0,0 +1,125 @@
+package winacl_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveRights(t *testing.T) {
+
+	r := require.New(t)
+
+	authUsers, _ := winacl.ParseSID("S-1-5-11")
+	system, _ := winacl.ParseSID("S-1-5-18")
+	personalInfo, _ := winacl.ParseGUID("77b5b886-944a-11d1-aebd-0000f80367c1")
+	userChangePassword, _ := winacl.ParseGUID("ab721a53-1e2f-11d0-9819-00aa0040529b")
+
+	ntsd, err := winacl.NewDescriptor().
+		Allow(authUsers, winacl.AccessMaskReadControl).
+		DenyObject(authUsers, winacl.ADSRightDSWriteProp, personalInfo, winacl.GUID{}).
+		Allow(authUsers, winacl.ADSRightDSReadProp|winacl.ADSRightDSWriteProp).
+		Deny(authUsers, winacl.AccessMaskDelete).
+		AllowObject(authUsers, winacl.ADSRightDSControlAccess, userChangePassword, winacl.GUID{}).
+		AllowObject(authUsers, winacl.ADSRightDSReadProp, personalInfo, winacl.GUID{}).
+		Allow(system, winacl.AccessMaskGenericAll, winacl.ACEHeaderFlagsContainerInheritAce, winacl.ACEHeaderFlagsInheritOnlyAce).
+		Build()
+	r.NoError(err)
+
+	t.Run("Subtracts preceding denies from allows", func(t *testing.T) {
+		report := winacl.EffectiveRights(ntsd, winacl.ObjectClassUser)
+		r.Len(report.Principals, 1)
+
+		pr := report.Principals[0]
+		r.Equal(authUsers, pr.Principal)
+		r.Equal(uint32(winacl.AccessMaskReadControl|winacl.ADSRightDSReadProp|winacl.ADSRightDSWriteProp), pr.Rights.Raw())
+		r.Equal([]string{"DELETE"}, pr.Denied.StringSlice())
+
+		r.Len(pr.ObjectTypes, 2)
+		r.Equal("Personal-Information", pr.ObjectTypes[0].Name)
+		r.Equal(uint32(winacl.AccessMaskReadControl|winacl.ADSRightDSReadProp), pr.ObjectTypes[0].Rights.Raw())
+		r.Equal(uint32(winacl.AccessMaskDelete|winacl.ADSRightDSWriteProp), pr.ObjectTypes[0].Denied.Raw())
+		r.Equal("User-Change-Password", pr.ObjectTypes[1].Name)
+		r.NotZero(pr.ObjectTypes[1].Rights.Raw() & winacl.ADSRightDSControlAccess)
+	})
+
+	t.Run("Lists principals in DACL order", func(t *testing.T) {
+		report := winacl.EffectiveRights(newTestSD(), winacl.ObjectClassUser)
+		r.Equal("S-1-5-21-2333832797-2102143736-1942374753-553", report.Principals[0].Principal.String())
+
+		for _, pr := range report.Principals {
+			if pr.Principal.String() == "S-1-1-0" {
+				r.Zero(pr.Rights.Raw())
+				r.Len(pr.ObjectTypes, 1)
+				r.Equal("User-Change-Password", pr.ObjectTypes[0].Name)
+			}
+		}
+	})
+
+	t.Run("Applies property set denies to member attributes", func(t *testing.T) {
+		telephoneNumber, _ := winacl.ParseGUID("bf967a49-0de6-11d0-a285-00aa003049e2")
+		sd, err := winacl.NewDescriptor().
+			DenyObject(authUsers, winacl.ADSRightDSWriteProp, personalInfo, winacl.GUID{}).
+			AllowObject(authUsers, winacl.ADSRightDSReadProp|winacl.ADSRightDSWriteProp, telephoneNumber, winacl.GUID{}).
+			Build()
+		r.NoError(err)
+
+		pr := winacl.EffectiveRights(sd, winacl.ObjectClassUser).Principals[0]
+		r.Len(pr.ObjectTypes, 2)
+		r.Equal("Telephone-Number", pr.ObjectTypes[1].Name)
+		r.Equal(uint32(winacl.ADSRightDSReadProp), pr.ObjectTypes[1].Rights.Raw())
+		r.Equal(uint32(winacl.ADSRightDSWriteProp), pr.ObjectTypes[1].Denied.Raw())
+	})
+
+	t.Run("Skips ACEs scoped to another class", func(t *testing.T) {
+		computer, _ := winacl.ParseGUID("bf967a86-0de6-11d0-a285-00aa003049e2")
+		sd, err := winacl.NewDescriptor().
+			AllowObject(authUsers, winacl.AccessMaskWriteDACL, winacl.GUID{}, computer).
+			Build()
+		r.NoError(err)
+
+		r.Empty(winacl.EffectiveRights(sd, winacl.ObjectClassUser).Principals)
+		r.Len(winacl.EffectiveRights(sd, winacl.ObjectClassComputer).Principals, 1)
+		r.Len(winacl.EffectiveRights(sd, "").Principals, 1)
+	})
+
+	t.Run("Applies ACEs scoped to a superclass", func(t *testing.T) {
+		user, _ := winacl.ParseGUID("bf967aba-0de6-11d0-a285-00aa003049e2")
+		sd, err := winacl.NewDescriptor().
+			AllowObject(authUsers, winacl.AccessMaskWriteDACL, winacl.GUID{}, user).
+			Build()
+		r.NoError(err)
+
+		report := winacl.EffectiveRights(sd, "inetOrgPerson")
+		r.Len(report.Principals, 1)
+		r.Equal(uint32(winacl.AccessMaskWriteDACL), report.Principals[0].Rights.Raw())
+		r.Empty(winacl.EffectiveRights(sd, "contact").Principals)
+		r.Len(winacl.EffectiveRights(sd, "corpWidget").Principals, 1)
+	})
+
+	t.Run("Renders as JSON and text", func(t *testing.T) {
+		report := winacl.EffectiveRights(ntsd, winacl.ObjectClassUser)
+
+		encoded, err := json.Marshal(report)
+		r.NoError(err)
+		r.Contains(string(encoded), `"principal":{"sid":"S-1-5-11","name":"Authenticated Users"}`)
+		r.Contains(string(encoded), `"objectType":"77b5b886-944a-11d1-aebd-0000f80367c1"`)
+
+		lines := strings.Split(strings.TrimSpace(report.String()), "\n")
+		r.Len(lines, 4)
+		r.True(strings.HasPrefix(lines[0], "PRINCIPAL"))
+		r.Contains(lines[1], "(all)")
+		r.Contains(lines[2], "Personal-Information")
+	})
+
+	t.Run("Reports full control for Everyone on a NULL DACL", func(t *testing.T) {
+		report := winacl.EffectiveRights(winacl.NtSecurityDescriptor{}, winacl.ObjectClassUser)
+		r.Len(report.Principals, 1)
+		r.Equal("S-1-1-0", report.Principals[0].Principal.String())
+		r.Equal(uint32(0x000F01FF), report.Principals[0].Rights.Raw())
+	})
+
+}
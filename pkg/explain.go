@@ -0,0 +1,265 @@
+package winacl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Explainer renders descriptors and ACEs as English sentences, in the
+// spirit of the Advanced Security Settings dialog. Resolver names object
+// types, and SIDNames overrides the names of principals, e.g. with
+// "CORP\Helpdesk" for a domain group; other principals are named with
+// SID.Resolve
+type Explainer struct {
+	Resolver *GUIDResolver
+	SIDNames map[string]string
+}
+
+// rightPhrases describe the rights of an ACE without an ObjectType
+var rightPhrases = []struct {
+	right  uint32
+	phrase string
+}{
+	{AccessMaskGenericRead, "generic read"},
+	{AccessMaskGenericWrite, "generic write"},
+	{AccessMaskGenericExecute, "generic execute"},
+	{AccessMaskMaximumAllowed, "maximum allowed"},
+	{AccessMaskSystemSecurity, "access the SACL"},
+	{AccessMaskSynchronize, "synchronize"},
+	{ADSRightDSListChildrend, "list contents"},
+	{ADSRightDSReadProp, "read all properties"},
+	{ADSRightDSWriteProp, "write all properties"},
+	{ADSRightDSCreateChild, "create all child objects"},
+	{ADSRightDSDeleteChild, "delete all child objects"},
+	{ADSRightDSSelf, "all validated writes"},
+	{ADSRightDSControlAccess, "all extended rights"},
+	{ADSRightDSListObject, "list object"},
+	{ADSRightDSDeleteTree, "delete subtree"},
+	{AccessMaskDelete, "delete"},
+	{AccessMaskReadControl, "read permissions"},
+	{AccessMaskWriteDACL, "modify permissions"},
+	{AccessMaskWriteOwner, "modify owner"},
+}
+
+// controlAccessPhrases describe well-known extended rights and
+// validated writes, keyed by rightsGuid. Validated writes are keyed by
+// GUID as most share it with, and resolve to, the attribute they guard
+var controlAccessPhrases = map[string]string{
+	"00299570-246d-11d0-a768-00aa006e0529": "reset password",                              // User-Force-Change-Password
+	"ab721a53-1e2f-11d0-9819-00aa0040529b": "change password",                             // User-Change-Password
+	"1131f6aa-9c07-11d1-f79f-00c04fc2dcd2": "replicate directory changes",                 // DS-Replication-Get-Changes
+	"1131f6ad-9c07-11d1-f79f-00c04fc2dcd2": "replicate directory changes all",             // DS-Replication-Get-Changes-All
+	"89e95b76-444d-4c62-991a-0facbeda640c": "replicate directory changes in filtered set", // DS-Replication-Get-Changes-In-Filtered-Set
+	"ab721a54-1e2f-11d0-9819-00aa0040529b": "send as",                                     // Send-As
+	"ab721a56-1e2f-11d0-9819-00aa0040529b": "receive as",                                  // Receive-As
+	"68b1d179-0d15-4d4f-ab71-46152e79a7bc": "be allowed to authenticate",                  // Allowed-To-Authenticate
+	"bf9679c0-0de6-11d0-a285-00aa003049e2": "add/remove self as member",                   // Self-Membership
+	"f3a64788-5306-11d1-a9c5-0000f80367c1": "validated write to service principal name",   // Validated-SPN
+	"72e39547-7b18-11d1-adef-00c04fd8d5cd": "validated write to DNS host name",            // Validated-DNS-Host-Name
+}
+
+// Explain renders the descriptor with the built-in GUID tables
+func (s NtSecurityDescriptor) Explain() string {
+	return Explainer{}.Explain(s)
+}
+
+// Explain renders the ACE as a sentence with the built-in GUID tables
+func (s ACE) Explain() string {
+	return Explainer{}.ExplainAce(s)
+}
+
+// Explain renders the owner, group and every ACE of a descriptor, one
+// sentence per line
+func (e Explainer) Explain(ntsd NtSecurityDescriptor) string {
+	sb := strings.Builder{}
+	if ntsd.Owner.String() != "" {
+		fmt.Fprintf(&sb, "Owner: %s\n", e.principalName(ntsd.Owner))
+	}
+	if ntsd.Group.String() != "" {
+		fmt.Fprintf(&sb, "Primary group: %s\n", e.principalName(ntsd.Group))
+	}
+	if ntsd.Header.Control&DACLProtected != 0 {
+		sb.WriteString("Permissions are not inherited from the parent object\n")
+	}
+
+	if ntsd.DACL == nil {
+		sb.WriteString("No DACL: everyone has full control\n")
+	}
+	for _, ace := range ntsd.daclAces() {
+		fmt.Fprintf(&sb, "%s\n", e.ExplainAce(ace))
+	}
+	for _, ace := range ntsd.SACL.Aces {
+		fmt.Fprintf(&sb, "%s\n", e.ExplainAce(ace))
+	}
+	return sb.String()
+}
+
+// ExplainAce renders an ACE as a sentence, such as "Allow Account
+// Operators to reset password on descendant User objects, inherited"
+func (e Explainer) ExplainAce(ace ACE) string {
+	if ace.ObjectAce == nil {
+		return fmt.Sprintf("Unsupported %s entry", ace.GetTypeString())
+	}
+
+	var verb string
+	switch {
+	case isAllowACE(ace):
+		verb = "Allow"
+	case isDenyACE(ace):
+		verb = "Deny"
+	case isAuditACE(ace):
+		verb = "Audit " + auditOutcomes(ace.Header.Flags) + " by"
+	default:
+		verb = ace.GetTypeString() + " for"
+	}
+
+	sentence := fmt.Sprintf("%s %s to %s on %s",
+		verb,
+		e.principalName(ace.ObjectAce.GetPrincipal()),
+		e.actions(ace),
+		e.scope(ace),
+	)
+	if ace.Header.Flags&ACEHeaderFlagsInheritedAce != 0 {
+		sentence += ", inherited"
+	}
+	return sentence
+}
+
+func (e Explainer) principalName(sid SID) string {
+	if name, found := e.SIDNames[sid.String()]; found {
+		return name
+	}
+	return sid.Resolve()
+}
+
+// actions describes the rights of an ACE, taking its ObjectType into
+// account
+func (e Explainer) actions(ace ACE) string {
+	mask := ace.AccessMask.Raw()
+	if mask&AccessMaskGenericAll != 0 || mask&adsRightsGenericAll == adsRightsGenericAll {
+		return "full control"
+	}
+
+	aa, isObject := ace.ObjectAce.(AdvancedAce)
+	if !isObject || aa.Flags&ACEInheritanceFlagsObjectTypePresent == 0 {
+		return joinPhrases(maskPhrases(mask))
+	}
+
+	info, found := e.Resolver.Lookup(aa.ObjectType)
+	if !found {
+		info = GUIDInfo{GUID: aa.ObjectType, Name: aa.ObjectType.String()}
+	}
+
+	phrases := []string{}
+	propertyName := info.Name
+	if mask&(ADSRightDSReadProp|ADSRightDSWriteProp) != 0 && info.Kind == GUIDKindValidatedWrite {
+		propertyName = e.Resolver.attributeInfo(aa.ObjectType).Name
+	}
+	switch mask & (ADSRightDSReadProp | ADSRightDSWriteProp) {
+	case ADSRightDSReadProp | ADSRightDSWriteProp:
+		phrases = append(phrases, "read and write "+propertyName)
+	case ADSRightDSReadProp:
+		phrases = append(phrases, "read "+propertyName)
+	case ADSRightDSWriteProp:
+		phrases = append(phrases, "write "+propertyName)
+	}
+	if mask&ADSRightDSCreateChild != 0 {
+		phrases = append(phrases, fmt.Sprintf("create %s objects", info.Name))
+	}
+	if mask&ADSRightDSDeleteChild != 0 {
+		phrases = append(phrases, fmt.Sprintf("delete %s objects", info.Name))
+	}
+	if mask&(ADSRightDSControlAccess|ADSRightDSSelf) != 0 {
+		phrases = append(phrases, controlAccessPhrase(info))
+	}
+
+	remaining := mask &^ (ADSRightDSReadProp | ADSRightDSWriteProp | ADSRightDSCreateChild |
+		ADSRightDSDeleteChild | ADSRightDSControlAccess | ADSRightDSSelf)
+	phrases = append(phrases, maskPhrases(remaining)...)
+	return joinPhrases(phrases)
+}
+
+// scope describes which objects an ACE applies to. An InheritedObjectType
+// narrows the descendants it is inherited by, not the object itself
+func (e Explainer) scope(ace ACE) string {
+	flags := ace.Header.Flags
+	inheritable := flags&(ACEHeaderFlagsContainerInheritAce|ACEHeaderFlagsObjectInheritAce) != 0
+	inheritOnly := flags&ACEHeaderFlagsInheritOnlyAce != 0
+	childrenOnly := flags&ACEHeaderFlagsNoPropogateInheritAce != 0
+
+	descendants := "all descendant objects"
+	if childrenOnly {
+		descendants = "child objects"
+	}
+	aa, isObject := ace.ObjectAce.(AdvancedAce)
+	classScoped := isObject && aa.Flags&ACEInheritanceFlagsInheritedObjectTypePresent != 0
+	if classScoped {
+		kind := "descendant"
+		if childrenOnly {
+			kind = "child"
+		}
+		descendants = fmt.Sprintf("%s %s objects", kind, e.Resolver.Resolve(aa.InheritedObjectType))
+	}
+
+	switch {
+	case !inheritable:
+		return "this object only"
+	case inheritOnly && classScoped:
+		return descendants
+	case inheritOnly:
+		return descendants + " only"
+	default:
+		return "this object and " + descendants
+	}
+}
+
+func auditOutcomes(flags ACEHeaderFlags) string {
+	success := flags&ACEHeaderFlagsSuccessfulAccessAceFlag != 0
+	failure := flags&ACEHeaderFlagsFailedAccessAceFlag != 0
+	switch {
+	case success && failure:
+		return "successful and failed attempts"
+	case success:
+		return "successful attempts"
+	case failure:
+		return "failed attempts"
+	default:
+		return "no attempts"
+	}
+}
+
+func controlAccessPhrase(info GUIDInfo) string {
+	if phrase, found := controlAccessPhrases[info.GUID.String()]; found {
+		return phrase
+	}
+	if info.Kind == GUIDKindValidatedWrite {
+		return fmt.Sprintf("perform the %s validated write", info.Name)
+	}
+	return fmt.Sprintf("use the %s extended right", info.Name)
+}
+
+func maskPhrases(mask uint32) []string {
+	phrases := []string{}
+	for _, rp := range rightPhrases {
+		if mask&rp.right != 0 {
+			phrases = append(phrases, rp.phrase)
+			mask &^= rp.right
+		}
+	}
+	if mask != 0 {
+		phrases = append(phrases, fmt.Sprintf("rights 0x%x", mask))
+	}
+	return phrases
+}
+
+// joinPhrases joins phrases as "a, b and c"
+func joinPhrases(phrases []string) string {
+	switch len(phrases) {
+	case 0:
+		return "nothing"
+	case 1:
+		return phrases[0]
+	default:
+		return strings.Join(phrases[:len(phrases)-1], ", ") + " and " + phrases[len(phrases)-1]
+	}
+}
@@ -0,0 +1,122 @@
+package winacl_test
+
+import (
+	"strings"
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+
+	r := require.New(t)
+
+	helpdesk, _ := winacl.ParseSID("S-1-5-21-1004336348-1177238915-682003330-1110")
+	everyone, _ := winacl.ParseSID("S-1-1-0")
+	system, _ := winacl.ParseSID("S-1-5-18")
+	resetPassword, _ := winacl.ParseGUID("00299570-246d-11d0-a768-00aa006e0529")
+	userClass, _ := winacl.ParseGUID("bf967aba-0de6-11d0-a285-00aa003049e2")
+	personalInfo, _ := winacl.ParseGUID("77b5b886-944a-11d1-aebd-0000f80367c1")
+
+	explainer := winacl.Explainer{
+		SIDNames: map[string]string{helpdesk.String(): `CORP\Helpdesk`},
+	}
+
+	t.Run("Describes object rights on descendant classes", func(t *testing.T) {
+		ace := winacl.BuildObjectAce(
+			winacl.AceTypeAccessAllowedObject,
+			winacl.ACEHeaderFlags(winacl.ACEHeaderFlagsContainerInheritAce|winacl.ACEHeaderFlagsInheritOnlyAce|winacl.ACEHeaderFlagsInheritedAce),
+			winacl.ADSRightDSControlAccess,
+			helpdesk,
+			resetPassword,
+			userClass,
+		)
+		r.Equal(`Allow CORP\Helpdesk to reset password on descendant User objects, inherited`, explainer.ExplainAce(ace))
+	})
+
+	t.Run("Combines descendant classes with the inheritance flags", func(t *testing.T) {
+		ace := winacl.BuildObjectAce(
+			winacl.AceTypeAccessAllowedObject,
+			winacl.ACEHeaderFlagsContainerInheritAce,
+			winacl.ADSRightDSControlAccess,
+			helpdesk,
+			resetPassword,
+			userClass,
+		)
+		r.Equal(`Allow CORP\Helpdesk to reset password on this object and descendant User objects`, explainer.ExplainAce(ace))
+
+		ace = winacl.BuildObjectAce(
+			winacl.AceTypeAccessAllowedObject,
+			winacl.ACEHeaderFlags(winacl.ACEHeaderFlagsContainerInheritAce|winacl.ACEHeaderFlagsNoPropogateInheritAce),
+			winacl.ADSRightDSControlAccess,
+			helpdesk,
+			resetPassword,
+			userClass,
+		)
+		r.Equal(`Allow CORP\Helpdesk to reset password on this object and child User objects`, explainer.ExplainAce(ace))
+
+		ace = winacl.BuildObjectAce(winacl.AceTypeAccessAllowedObject, 0, winacl.ADSRightDSControlAccess, helpdesk, resetPassword, userClass)
+		r.Equal(`Allow CORP\Helpdesk to reset password on this object only`, explainer.ExplainAce(ace))
+	})
+
+	t.Run("Describes property access and scopes", func(t *testing.T) {
+		ace := winacl.BuildObjectAce(
+			winacl.AceTypeAccessDeniedObject,
+			winacl.ACEHeaderFlagsContainerInheritAce,
+			winacl.ADSRightDSReadProp|winacl.ADSRightDSWriteProp,
+			everyone,
+			personalInfo,
+			winacl.GUID{},
+		)
+		r.Equal("Deny World/Everyone to read and write Personal-Information on this object and all descendant objects", ace.Explain())
+
+		ace = winacl.BuildBasicAce(winacl.AceTypeAccessAllowed, 0, winacl.AccessMaskReadControl|winacl.ADSRightDSListChildrend, everyone)
+		r.Equal("Allow World/Everyone to list contents and read permissions on this object only", ace.Explain())
+	})
+
+	t.Run("Describes validated writes sharing an attribute's GUID", func(t *testing.T) {
+		self, _ := winacl.ParseSID("S-1-5-10")
+		spn, _ := winacl.ParseGUID("f3a64788-5306-11d1-a9c5-0000f80367c1")
+
+		ace := winacl.BuildObjectAce(winacl.AceTypeAccessAllowedObject, 0, winacl.ADSRightDSSelf, self, spn, winacl.GUID{})
+		r.True(strings.HasSuffix(ace.Explain(), " to validated write to service principal name on this object only"))
+
+		ace = winacl.BuildObjectAce(winacl.AceTypeAccessAllowedObject, 0, winacl.ADSRightDSWriteProp, self, spn, winacl.GUID{})
+		r.True(strings.HasSuffix(ace.Explain(), " to write Service-Principal-Name on this object only"))
+	})
+
+	t.Run("Describes full control and audits", func(t *testing.T) {
+		ace := winacl.BuildBasicAce(winacl.AceTypeAccessAllowed, 0, winacl.AccessMaskGenericAll, system)
+		r.True(strings.HasSuffix(ace.Explain(), " to full control on this object only"))
+
+		ace = winacl.BuildBasicAce(
+			winacl.AceTypeSystemAudit,
+			winacl.ACEHeaderFlags(winacl.ACEHeaderFlagsSuccessfulAccessAceFlag|winacl.ACEHeaderFlagsFailedAccessAceFlag),
+			winacl.ADSRightDSWriteProp,
+			everyone,
+		)
+		r.Equal("Audit successful and failed attempts by World/Everyone to write all properties on this object only", ace.Explain())
+	})
+
+	t.Run("Explains a whole descriptor", func(t *testing.T) {
+		ntsd, err := winacl.NewDescriptor().
+			Owner(helpdesk).
+			Protected().
+			Allow(everyone, winacl.AccessMaskReadControl).
+			Audit(everyone, winacl.AccessMaskWriteDACL, winacl.ACEHeaderFlagsSuccessfulAccessAceFlag).
+			Build()
+		r.NoError(err)
+
+		lines := strings.Split(strings.TrimSpace(explainer.Explain(ntsd)), "\n")
+		r.Equal([]string{
+			`Owner: CORP\Helpdesk`,
+			"Permissions are not inherited from the parent object",
+			"Allow World/Everyone to read permissions on this object only",
+			"Audit successful attempts by World/Everyone to modify permissions on this object only",
+		}, lines)
+
+		ntsd.DACL = nil
+		r.Contains(explainer.Explain(ntsd), "No DACL: everyone has full control\n")
+	})
+}
@@ -0,0 +1,211 @@
+package winacl
+
+import (
+	"strings"
+)
+
+// Names of the ACLs an ACEMatch can come from
+const (
+	ACLNameDACL = "DACL"
+	ACLNameSACL = "SACL"
+)
+
+// ACEFilter reports whether an ACE should be selected. Filters compose
+// with AllOf, AnyOf and Not, e.g. the explicit ACEs granting WRITE_DAC
+// to principals outside of the built-in domain are selected by:
+//
+//	AllOf(MaskAny(AccessMaskWriteDACL), Not(PrincipalIn(IsBuiltinSID)), Explicit())
+type ACEFilter func(ace ACE) bool
+
+// ACEMatch is an ACE selected by a filter, along with the ACL it
+// belongs to and its position in that ACL
+type ACEMatch struct {
+	ACL   string
+	Index int
+	ACE   ACE
+}
+
+// FindAces returns the ACEs of the DACL, then of the SACL, selected by
+// the filter. A nil filter selects every ACE
+func (s NtSecurityDescriptor) FindAces(filter ACEFilter) []ACEMatch {
+	matches := []ACEMatch{}
+	if s.DACL != nil {
+		matches = s.DACL.findAces(ACLNameDACL, filter)
+	}
+	return append(matches, s.SACL.findAces(ACLNameSACL, filter)...)
+}
+
+// FilterAces returns the ACEs of the ACL selected by the filter
+func (a ACL) FilterAces(filter ACEFilter) []ACE {
+	aces := []ACE{}
+	for _, match := range a.findAces("", filter) {
+		aces = append(aces, match.ACE)
+	}
+	return aces
+}
+
+func (a ACL) findAces(name string, filter ACEFilter) []ACEMatch {
+	matches := []ACEMatch{}
+	for idx, ace := range a.Aces {
+		if filter == nil || filter(ace) {
+			matches = append(matches, ACEMatch{ACL: name, Index: idx, ACE: ace})
+		}
+	}
+	return matches
+}
+
+// AllOf selects ACEs selected by every one of the filters
+func AllOf(filters ...ACEFilter) ACEFilter {
+	return func(ace ACE) bool {
+		for _, filter := range filters {
+			if !filter(ace) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AnyOf selects ACEs selected by at least one of the filters
+func AnyOf(filters ...ACEFilter) ACEFilter {
+	return func(ace ACE) bool {
+		for _, filter := range filters {
+			if filter(ace) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not selects ACEs the filter does not select
+func Not(filter ACEFilter) ACEFilter {
+	return func(ace ACE) bool {
+		return !filter(ace)
+	}
+}
+
+// TypeIs selects ACEs of any of the given types
+func TypeIs(types ...AceType) ACEFilter {
+	return func(ace ACE) bool {
+		for _, aceType := range types {
+			if ace.Header.Type == aceType {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// PrincipalIs selects ACEs whose trustee is one of the given SIDs
+func PrincipalIs(sids ...SID) ACEFilter {
+	wanted := make(map[string]bool, len(sids))
+	for _, sid := range sids {
+		wanted[sid.String()] = true
+	}
+
+	return func(ace ACE) bool {
+		return ace.ObjectAce != nil && wanted[ace.ObjectAce.GetPrincipal().String()]
+	}
+}
+
+// PrincipalNamed selects ACEs whose trustee resolves to one of the given
+// names, compared case-insensitively, e.g. "Domain Admins" in any domain
+func PrincipalNamed(names ...string) ACEFilter {
+	return PrincipalIn(func(sid SID) bool {
+		resolved := sid.Resolve()
+		for _, name := range names {
+			if strings.EqualFold(resolved, name) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// PrincipalIn selects ACEs whose trustee belongs to a group of
+// principals, such as IsBuiltinSID, IsWellKnownSID or IsLowPrivilegedSID
+func PrincipalIn(group func(sid SID) bool) ACEFilter {
+	return func(ace ACE) bool {
+		return ace.ObjectAce != nil && group(ace.ObjectAce.GetPrincipal())
+	}
+}
+
+// IsBuiltinSID reports whether a SID belongs to the BUILTIN domain
+// (S-1-5-32-...)
+func IsBuiltinSID(sid SID) bool {
+	return strings.HasPrefix(sid.String(), "S-1-5-32-")
+}
+
+// IsWellKnownSID reports whether a SID resolves to a well-known name
+func IsWellKnownSID(sid SID) bool {
+	sidStr := sid.String()
+	return sidStr != "" && sid.Resolve() != sidStr
+}
+
+// MaskAny selects ACEs whose access mask contains any of the bits of mask
+func MaskAny(mask uint32) ACEFilter {
+	return func(ace ACE) bool {
+		return ace.AccessMask.Raw()&mask != 0
+	}
+}
+
+// MaskAll selects ACEs whose access mask contains every bit of mask
+func MaskAll(mask uint32) ACEFilter {
+	return func(ace ACE) bool {
+		return ace.AccessMask.Raw()&mask == mask
+	}
+}
+
+// ObjectTypeIs selects object ACEs whose ObjectType is one of the
+// given GUIDs
+func ObjectTypeIs(guids ...GUID) ACEFilter {
+	return func(ace ACE) bool {
+		aa, ok := ace.ObjectAce.(AdvancedAce)
+		return ok && aa.Flags&ACEInheritanceFlagsObjectTypePresent != 0 &&
+			containsGUID(guids, aa.ObjectType)
+	}
+}
+
+// InheritedObjectTypeIs selects object ACEs whose InheritedObjectType
+// is one of the given GUIDs
+func InheritedObjectTypeIs(guids ...GUID) ACEFilter {
+	return func(ace ACE) bool {
+		aa, ok := ace.ObjectAce.(AdvancedAce)
+		return ok && aa.Flags&ACEInheritanceFlagsInheritedObjectTypePresent != 0 &&
+			containsGUID(guids, aa.InheritedObjectType)
+	}
+}
+
+// FlagsAny selects ACEs whose header carries any of the given flags
+func FlagsAny(flags ACEHeaderFlags) ACEFilter {
+	return func(ace ACE) bool {
+		return ace.Header.Flags&flags != 0
+	}
+}
+
+// FlagsAll selects ACEs whose header carries every one of the given flags
+func FlagsAll(flags ACEHeaderFlags) ACEFilter {
+	return func(ace ACE) bool {
+		return ace.Header.Flags&flags == flags
+	}
+}
+
+// Inherited selects ACEs that were inherited from a parent object
+func Inherited() ACEFilter {
+	return FlagsAny(ACEHeaderFlagsInheritedAce)
+}
+
+// Explicit selects ACEs that were set directly on the object
+func Explicit() ACEFilter {
+	return Not(Inherited())
+}
+
+func containsGUID(guids []GUID, guid GUID) bool {
+	for _, candidate := range guids {
+		if candidate == guid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package winacl_test
+
+import (
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindAces(t *testing.T) {
+
+	r := require.New(t)
+	sd := newTestSD()
+
+	matchIndexes := func(matches []winacl.ACEMatch) []int {
+		indexes := []int{}
+		for _, match := range matches {
+			indexes = append(indexes, match.Index)
+		}
+		return indexes
+	}
+
+	t.Run("Composes filters", func(t *testing.T) {
+		matches := sd.FindAces(winacl.AllOf(
+			winacl.MaskAny(winacl.AccessMaskWriteDACL),
+			winacl.Not(winacl.PrincipalIn(winacl.IsBuiltinSID)),
+			winacl.Explicit(),
+		))
+		r.Equal([]int{19, 23}, matchIndexes(matches))
+		r.Equal(winacl.ACLNameDACL, matches[0].ACL)
+		r.Equal("Domain Admins", matches[0].ACE.ObjectAce.GetPrincipal().Resolve())
+
+		r.Len(sd.FindAces(nil), len(sd.DACL.Aces))
+		r.Len(sd.FindAces(winacl.AnyOf(winacl.Inherited(), winacl.Explicit())), len(sd.DACL.Aces))
+	})
+
+	t.Run("Filters on principals", func(t *testing.T) {
+		everyone, err := winacl.ParseSID("S-1-1-0")
+		r.NoError(err)
+		r.Equal([]int{8}, matchIndexes(sd.FindAces(winacl.PrincipalIs(everyone))))
+
+		named := sd.FindAces(winacl.PrincipalNamed("enterprise admins", "Domain Admins"))
+		r.Len(named, 2)
+		r.False(winacl.IsWellKnownSID(sd.DACL.Aces[5].ObjectAce.GetPrincipal()))
+	})
+
+	t.Run("Filters on object types and masks", func(t *testing.T) {
+		userChangePassword, err := winacl.ParseGUID("ab721a53-1e2f-11d0-9819-00aa0040529b")
+		r.NoError(err)
+		user, err := winacl.ParseGUID("bf967aba-0de6-11d0-a285-00aa003049e2")
+		r.NoError(err)
+
+		r.Equal([]int{8, 9}, matchIndexes(sd.FindAces(winacl.ObjectTypeIs(userChangePassword))))
+
+		inheritedByUsers := sd.DACL.FilterAces(winacl.AllOf(
+			winacl.InheritedObjectTypeIs(user),
+			winacl.MaskAll(winacl.ADSRightDSReadProp|winacl.ADSRightDSListChildrend),
+		))
+		r.Len(inheritedByUsers, 1)
+		r.Equal("(OA;CIID;LCRPLORC;;bf967aba-0de6-11d0-a285-00aa003049e2;RU)", inheritedByUsers[0].ToSDDL())
+
+		r.Len(sd.FindAces(winacl.AllOf(
+			winacl.TypeIs(winacl.AceTypeAccessAllowedObject),
+			winacl.FlagsAll(winacl.ACEHeaderFlagsInheritOnlyAce|winacl.ACEHeaderFlagsInheritedAce),
+		)), 12)
+	})
+
+	t.Run("Searches the SACL after the DACL", func(t *testing.T) {
+		audit, err := newTestBasicACE(winacl.AceTypeSystemAudit, winacl.ACEHeaderFlagsFailedAccessAceFlag, winacl.AccessMaskWriteDACL, "S-1-1-0")
+		r.NoError(err)
+
+		audited := newTestSD()
+		audited.SACL = winacl.ACL{Aces: []winacl.ACE{audit}}
+
+		matches := audited.FindAces(winacl.MaskAny(winacl.AccessMaskWriteDACL))
+		r.Len(matches, 6)
+		r.Equal(winacl.ACLNameSACL, matches[5].ACL)
+		r.Equal(0, matches[5].Index)
+	})
+
+}
@@ -0,0 +1,269 @@
+package winacl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity ranks how serious a finding or diagnostic is
+type Severity byte
+
+const (
+	SeverityInfo Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+// SeverityLookup maps Severities to a human-readable labels
+var SeverityLookup = map[Severity]string{
+	SeverityInfo:     "INFO",
+	SeverityLow:      "LOW",
+	SeverityMedium:   "MEDIUM",
+	SeverityHigh:     "HIGH",
+	SeverityCritical: "CRITICAL",
+}
+
+// String returns a Severity's human-readable label
+func (s Severity) String() string {
+	return SeverityLookup[s]
+}
+
+// RuleContext describes the object a descriptor was read from. Rules use
+// it to scope themselves, e.g. to organizational units or to objects
+// the caller considers privileged (AdminSDHolder-protected, tier 0)
+type RuleContext struct {
+	DN          string
+	ObjectClass string
+	Privileged  bool
+}
+
+// Finding is a risky permission reported by a Rule. AceIndex is the
+// offending ACE's position in the DACL, or -1 when the finding concerns
+// the descriptor as a whole
+type Finding struct {
+	RuleID    string
+	Severity  Severity
+	Rationale string
+	Principal SID
+	AceIndex  int
+	Ace       *ACE
+}
+
+// String returns an human-readable representation of a Finding
+func (f Finding) String() string {
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "[%s] %s: %s", f.Severity, f.RuleID, f.Rationale)
+	if f.Principal.String() != "" {
+		fmt.Fprintf(&sb, " (%s)", f.Principal.Resolve())
+	}
+	if f.Ace != nil {
+		fmt.Fprintf(&sb, " %s", f.Ace.ToSDDL())
+	}
+	return sb.String()
+}
+
+// Rule is a check run by the FindingsEngine against a descriptor
+type Rule interface {
+	ID() string
+	Evaluate(ntsd NtSecurityDescriptor, ctx RuleContext) []Finding
+}
+
+// ACERule reports every DACL entry for which Match returns true
+type ACERule struct {
+	RuleID    string
+	Severity  Severity
+	Rationale string
+	Match     func(ace ACE, ctx RuleContext) bool
+}
+
+// ID returns the rule's identifier
+func (r ACERule) ID() string {
+	return r.RuleID
+}
+
+// Evaluate reports the DACL entries matched by the rule
+func (r ACERule) Evaluate(ntsd NtSecurityDescriptor, ctx RuleContext) []Finding {
+	findings := []Finding{}
+	aces := ntsd.daclAces()
+	for idx := range aces {
+		ace := aces[idx]
+		if ace.ObjectAce == nil || !r.Match(ace, ctx) {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			RuleID:    r.RuleID,
+			Severity:  r.Severity,
+			Rationale: r.Rationale,
+			Principal: ace.ObjectAce.GetPrincipal(),
+			AceIndex:  idx,
+			Ace:       &ace,
+		})
+	}
+	return findings
+}
+
+// DescriptorRule reports a single finding when Check returns true
+type DescriptorRule struct {
+	RuleID    string
+	Severity  Severity
+	Rationale string
+	Check     func(ntsd NtSecurityDescriptor, ctx RuleContext) bool
+}
+
+// ID returns the rule's identifier
+func (r DescriptorRule) ID() string {
+	return r.RuleID
+}
+
+// Evaluate reports the descriptor if it fails the rule's check
+func (r DescriptorRule) Evaluate(ntsd NtSecurityDescriptor, ctx RuleContext) []Finding {
+	if !r.Check(ntsd, ctx) {
+		return nil
+	}
+	return []Finding{{
+		RuleID:    r.RuleID,
+		Severity:  r.Severity,
+		Rationale: r.Rationale,
+		Principal: ntsd.Owner,
+		AceIndex:  -1,
+	}}
+}
+
+// FindingsEngine runs a set of rules over descriptors
+type FindingsEngine struct {
+	rules []Rule
+}
+
+// NewFindingsEngine is a constructor for a FindingsEngine running the
+// given rules, e.g. NewFindingsEngine(ADRules()...)
+func NewFindingsEngine(rules ...Rule) *FindingsEngine {
+	return &FindingsEngine{rules: rules}
+}
+
+// AddRules registers additional rules with the engine
+func (e *FindingsEngine) AddRules(rules ...Rule) {
+	e.rules = append(e.rules, rules...)
+}
+
+// Rules returns the rules registered with the engine
+func (e *FindingsEngine) Rules() []Rule {
+	return e.rules
+}
+
+// Evaluate runs every rule against a descriptor and returns the findings,
+// most severe first
+func (e *FindingsEngine) Evaluate(ntsd NtSecurityDescriptor, ctx RuleContext) []Finding {
+	findings := []Finding{}
+	for _, rule := range e.rules {
+		findings = append(findings, rule.Evaluate(ntsd, ctx)...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity > findings[j].Severity
+	})
+	return findings
+}
+
+// LowPrivilegedSIDs are well-known principals that every, or nearly
+// every, user of a domain or machine is a member of
+var LowPrivilegedSIDs = map[string]bool{
+	"S-1-1-0":      true, // Everyone
+	"S-1-5-2":      true, // Network
+	"S-1-5-4":      true, // Interactive
+	"S-1-5-7":      true, // Anonymous
+	"S-1-5-11":     true, // Authenticated Users
+	"S-1-5-32-545": true, // Built-in Users
+	"S-1-5-32-546": true, // Built-in Guests
+	"S-1-5-32-554": true, // Pre-Windows 2000 Compatible Access
+}
+
+// lowPrivilegedDomainRIDs are the domain relative identifiers of
+// groups every user or computer of a domain belongs to
+var lowPrivilegedDomainRIDs = map[uint32]bool{
+	513: true, // Domain Users
+	514: true, // Domain Guests
+	515: true, // Domain Computers
+}
+
+// AdministrativeSIDs are well-known principals that already
+// administer the objects they appear on
+var AdministrativeSIDs = map[string]bool{
+	"S-1-5-9":      true, // Enterprise Domain Controllers
+	"S-1-5-18":     true, // Local System
+	"S-1-5-32-544": true, // Built-in Administrators
+}
+
+// administrativeDomainRIDs are the domain relative identifiers of
+// the administrative accounts and groups of a domain
+var administrativeDomainRIDs = map[uint32]bool{
+	500: true, // Administrator
+	512: true, // Domain Admins
+	516: true, // Domain Controllers
+	518: true, // Schema Admins
+	519: true, // Enterprise Admins
+}
+
+// IsLowPrivilegedSID reports whether a SID is a broad, low privileged
+// group such as Everyone, Authenticated Users or Domain Users
+func IsLowPrivilegedSID(sid SID) bool {
+	return LowPrivilegedSIDs[sid.String()] || lowPrivilegedDomainRIDs[domainRID(sid)]
+}
+
+// IsAdministrativeSID reports whether a SID is an administrative
+// principal such as SYSTEM, Administrators or Domain Admins
+func IsAdministrativeSID(sid SID) bool {
+	return AdministrativeSIDs[sid.String()] || administrativeDomainRIDs[domainRID(sid)]
+}
+
+// domainRID returns the relative identifier of a domain SID
+// (S-1-5-21-x-y-z-RID), or 0 for any other SID
+func domainRID(sid SID) uint32 {
+	if len(sid.Authority) < 6 || sid.Authority[5] != 5 || len(sid.SubAuthorities) != 5 || sid.SubAuthorities[0] != 21 {
+		return 0
+	}
+	return sid.SubAuthorities[4]
+}
+
+// isAllowACE reports whether an ACE grants access
+func isAllowACE(ace ACE) bool {
+	switch ace.Header.Type {
+	case AceTypeAccessAllowed, AceTypeAccessAllowedObject, AceTypeAccessAllowedCallback, AceTypeAccessAllowedCallbackObject:
+		return true
+	}
+	return false
+}
+
+// grantsOnObject reports whether an ACE grants access on the object
+// it is attached to, for every property and right of that object
+func grantsOnObject(ace ACE) bool {
+	if !isAllowACE(ace) || ace.Header.Flags&ACEHeaderFlagsInheritOnlyAce != 0 {
+		return false
+	}
+	aa, ok := ace.ObjectAce.(AdvancedAce)
+	return !ok || aa.Flags&ACEInheritanceFlagsObjectTypePresent == 0
+}
+
+// lowPrivilegedGrant matches ACEs granting a low privileged principal
+// any of the rights in mask on the object itself
+func lowPrivilegedGrant(mask uint32) func(ace ACE, ctx RuleContext) bool {
+	return func(ace ACE, ctx RuleContext) bool {
+		return grantsOnObject(ace) &&
+			IsLowPrivilegedSID(ace.ObjectAce.GetPrincipal()) &&
+			ace.AccessMask.Raw()&mask != 0
+	}
+}
+
+// lowPrivilegedFullControl matches ACEs granting a low privileged
+// principal every right in fullControl, or GENERIC_ALL
+func lowPrivilegedFullControl(fullControl uint32) func(ace ACE, ctx RuleContext) bool {
+	return func(ace ACE, ctx RuleContext) bool {
+		rights := ace.AccessMask.Raw()
+		return grantsOnObject(ace) &&
+			IsLowPrivilegedSID(ace.ObjectAce.GetPrincipal()) &&
+			(rights&AccessMaskGenericAll != 0 || rights&fullControl == fullControl)
+	}
+}
@@ -0,0 +1,125 @@
+package winacl_test
+
+import (
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindingsEngine(t *testing.T) {
+
+	r := require.New(t)
+
+	t.Run("Reports risky ACEs with the offending entry", func(t *testing.T) {
+		everyoneAll, err := newTestBasicACE(winacl.AceTypeAccessAllowed, 0, winacl.AccessMaskGenericAll, "S-1-1-0")
+		r.NoError(err)
+		authUsersWriteDacl, err := newTestBasicACE(winacl.AceTypeAccessAllowed, 0, winacl.AccessMaskWriteDACL, "S-1-5-11")
+		r.NoError(err)
+
+		sd := newTestSD()
+		sd.DACL.Aces = append(sd.DACL.Aces, authUsersWriteDacl, everyoneAll)
+
+		engine := winacl.NewFindingsEngine(winacl.ADRules()...)
+		findings := engine.Evaluate(sd, winacl.RuleContext{ObjectClass: winacl.ObjectClassUser})
+		r.Len(findings, 2)
+
+		r.Equal("ad-lowpriv-generic-all", findings[0].RuleID)
+		r.Equal(winacl.SeverityCritical, findings[0].Severity)
+		r.Equal(len(sd.DACL.Aces)-1, findings[0].AceIndex)
+		r.Equal(everyoneAll, *findings[0].Ace)
+
+		r.Equal("ad-lowpriv-write-dacl", findings[1].RuleID)
+		r.Equal("S-1-5-11", findings[1].Principal.String())
+	})
+
+	t.Run("Skips inherit-only ACEs and object-scoped rights", func(t *testing.T) {
+		inheritOnly, err := newTestBasicACE(winacl.AceTypeAccessAllowed, winacl.ACEHeaderFlagsInheritOnlyAce, winacl.AccessMaskWriteDACL, "S-1-1-0")
+		r.NoError(err)
+
+		sd := winacl.NtSecurityDescriptor{DACL: &winacl.ACL{Aces: []winacl.ACE{inheritOnly}}}
+		engine := winacl.NewFindingsEngine(winacl.ADRules()...)
+		r.Empty(engine.Evaluate(sd, winacl.RuleContext{}))
+		r.Empty(engine.Evaluate(newTestSD(), winacl.RuleContext{ObjectClass: winacl.ObjectClassUser}))
+	})
+
+	t.Run("Matches object classes case-insensitively", func(t *testing.T) {
+		createChild, err := newTestBasicACE(winacl.AceTypeAccessAllowed, 0, winacl.ADSRightDSCreateChild, "S-1-5-11")
+		r.NoError(err)
+		sd := winacl.NtSecurityDescriptor{DACL: &winacl.ACL{Aces: []winacl.ACE{createChild}}}
+
+		engine := winacl.NewFindingsEngine(winacl.ADRules()...)
+		findings := engine.Evaluate(sd, winacl.RuleContext{ObjectClass: "organizationalunit"})
+		r.Len(findings, 1)
+		r.Equal("ad-lowpriv-create-child", findings[0].RuleID)
+		r.Empty(engine.Evaluate(sd, winacl.RuleContext{ObjectClass: winacl.ObjectClassUser}))
+	})
+
+	t.Run("Flags non-administrative owners of privileged objects", func(t *testing.T) {
+		sd := newTestSD()
+		engine := winacl.NewFindingsEngine(winacl.ADRules()...)
+		r.Empty(engine.Evaluate(sd, winacl.RuleContext{Privileged: true}))
+
+		sd.Owner, _ = winacl.ParseSID("S-1-5-21-2333832797-2102143736-1942374753-1105")
+		findings := engine.Evaluate(sd, winacl.RuleContext{Privileged: true})
+		r.Len(findings, 1)
+		r.Equal("ad-privileged-non-admin-owner", findings[0].RuleID)
+		r.Equal(-1, findings[0].AceIndex)
+		r.Nil(findings[0].Ace)
+	})
+
+	t.Run("Runs custom rules alongside the built-in packs", func(t *testing.T) {
+		changeConfig, err := newTestBasicACE(winacl.AceTypeAccessAllowed, 0, winacl.ServiceChangeConfig|winacl.ServiceStart, "S-1-5-32-545")
+		r.NoError(err)
+		sd := winacl.NtSecurityDescriptor{DACL: &winacl.ACL{Aces: []winacl.ACE{changeConfig}}}
+
+		engine := winacl.NewFindingsEngine(winacl.ServiceRules()...)
+		engine.AddRules(winacl.DescriptorRule{
+			RuleID:   "custom-missing-owner",
+			Severity: winacl.SeverityInfo,
+			Check: func(ntsd winacl.NtSecurityDescriptor, ctx winacl.RuleContext) bool {
+				return ntsd.Owner.String() == ""
+			},
+		})
+
+		findings := engine.Evaluate(sd, winacl.RuleContext{})
+		ids := []string{}
+		for _, finding := range findings {
+			ids = append(ids, finding.RuleID)
+		}
+		r.Equal([]string{"svc-lowpriv-change-config", "svc-lowpriv-start-stop", "custom-missing-owner"}, ids)
+	})
+
+	t.Run("Reports NULL DACLs in every rule pack", func(t *testing.T) {
+		for id, rules := range map[string][]winacl.Rule{
+			"ad-null-dacl":  winacl.ADRules(),
+			"fs-null-dacl":  winacl.FileShareRules(),
+			"svc-null-dacl": winacl.ServiceRules(),
+		} {
+			findings := winacl.NewFindingsEngine(rules...).Evaluate(winacl.NtSecurityDescriptor{}, winacl.RuleContext{})
+			r.Len(findings, 1)
+			r.Equal(id, findings[0].RuleID)
+			r.Equal(winacl.SeverityCritical, findings[0].Severity)
+			r.Equal("S-1-1-0", findings[0].Principal.String())
+		}
+	})
+
+}
+
+func TestPrincipalClassification(t *testing.T) {
+
+	r := require.New(t)
+
+	for sidStr, lowPrivileged := range map[string]bool{
+		"S-1-1-0":            true,
+		"S-1-5-21-1-2-3-513": true,
+		"S-1-5-21-1-2-3-512": false,
+		"S-1-5-32-544":       false,
+	} {
+		sid, err := winacl.ParseSID(sidStr)
+		r.NoError(err)
+		r.Equal(lowPrivileged, winacl.IsLowPrivilegedSID(sid), sidStr)
+		r.Equal(!lowPrivileged, winacl.IsAdministrativeSID(sid), sidStr)
+	}
+
+}
@@ -3,7 +3,10 @@ package winacl
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/hex"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // GUID holds the various parts of a GUID
@@ -36,16 +39,66 @@ func NewGUID(buf *bytes.Buffer) (guid GUID, err error) {
 
 // String will return the human-readable version of a GUID
 // It returns an empty string in case of a null-initialized
-// GUID
+// GUID; use IsNull to test for it and MarshalText for a
+// representation that is never empty
 func (g GUID) String() string {
-	guid := fmt.Sprintf("%08x-%04x-%04x-%04x-%012x",
-		g.Data1, g.Data2, g.Data3, g.Data4[0:2], g.Data4[2:8])
+	guid := g.canonical()
 	if guid == nullGUID {
 		guid = ""
 	}
 	return guid
 }
 
+func (g GUID) canonical() string {
+	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x",
+		g.Data1, g.Data2, g.Data3, g.Data4[0:2], g.Data4[2:8])
+}
+
+// IsNull reports whether the GUID is the null GUID,
+// 00000000-0000-0000-0000-000000000000
+func (g GUID) IsNull() bool {
+	return g == GUID{}
+}
+
+// MarshalBinary encodes the GUID in its 16 byte mixed-endian wire
+// format, as read by NewGUID
+func (g GUID) MarshalBinary() ([]byte, error) {
+	buf := bytes.Buffer{}
+	err := binary.Write(&buf, binary.LittleEndian, g)
+	return buf.Bytes(), err
+}
+
+// UnmarshalBinary decodes a GUID from its 16 byte mixed-endian
+// wire format
+func (g *GUID) UnmarshalBinary(data []byte) error {
+	if len(data) != 16 {
+		return GUIDInvalidError{fmt.Sprintf("invalid GUID length %d", len(data))}
+	}
+
+	guid, err := NewGUID(bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	*g = guid
+	return nil
+}
+
+// MarshalText encodes the GUID in its canonical string form.
+// Unlike String, the null GUID is written out in full
+func (g GUID) MarshalText() ([]byte, error) {
+	return []byte(g.canonical()), nil
+}
+
+// UnmarshalText decodes a GUID using ParseGUID
+func (g *GUID) UnmarshalText(text []byte) error {
+	guid, err := ParseGUID(string(text))
+	if err != nil {
+		return err
+	}
+	*g = guid
+	return nil
+}
+
 // Resolve returns the common human-readable Object name as
 // defined by Microsoft. If the GUID is not resolvable, the
 // GUID string will be returned instead
@@ -60,10 +113,62 @@ func (g GUID) Resolve() string {
 	return guid
 }
 
+// LookupGUIDByName returns the built-in GUID registered under a name,
+// compared case-insensitively. See GUIDResolver.LookupName to include
+// GUIDs loaded from a directory's schema
+func LookupGUIDByName(name string) (GUIDInfo, error) {
+	var builtin *GUIDResolver
+	return builtin.LookupName(name)
+}
+
+// ParseGUID parses the xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx string
+// form of a GUID, as returned by String, optionally enclosed in braces
+func ParseGUID(s string) (guid GUID, err error) {
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = s[1 : len(s)-1]
+	}
+	if len(s) != len(nullGUID) || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return guid, GUIDInvalidError{fmt.Sprintf("invalid GUID format %q", s)}
+	}
+
+	data1, err := strconv.ParseUint(s[0:8], 16, 32)
+	if err != nil {
+		return guid, GUIDInvalidError{fmt.Sprintf("invalid GUID %q", s)}
+	}
+	data2, err := strconv.ParseUint(s[9:13], 16, 16)
+	if err != nil {
+		return guid, GUIDInvalidError{fmt.Sprintf("invalid GUID %q", s)}
+	}
+	data3, err := strconv.ParseUint(s[14:18], 16, 16)
+	if err != nil {
+		return guid, GUIDInvalidError{fmt.Sprintf("invalid GUID %q", s)}
+	}
+	data4, err := hex.DecodeString(s[19:23] + s[24:36])
+	if err != nil {
+		return guid, GUIDInvalidError{fmt.Sprintf("invalid GUID %q", s)}
+	}
+
+	guid.Data1 = uint32(data1)
+	guid.Data2 = uint16(data2)
+	guid.Data3 = uint16(data3)
+	copy(guid.Data4[:], data4)
+	return guid, nil
+}
+
+type GUIDInvalidError struct{ msg string }
+
+func (e GUIDInvalidError) Error() string {
+	return fmt.Sprintf("ParseGUID: %s", e.msg)
+}
+
 // GUIDS is a map of all known pre-existing guids
-var GUIDS = map[string]string{
-	// Control Rights
-	// # https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-adts/1522b774-6464-41a3-87a5-1e5633c3fbbb
+var GUIDS = mergeGUIDTables(ControlAccessRightGUIDs, AttributeGUIDs, ClassGUIDs)
+
+// ControlAccessRightGUIDs maps the rightsGuid of the default extended
+// rights, property sets and validated writes to their names
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-adts/1522b774-6464-41a3-87a5-1e5633c3fbbb
+var ControlAccessRightGUIDs = map[string]string{
 	"ee914b82-0a98-11d1-adbb-00c04fd8d5cd": "Abandon-Replication",
 	"440820ad-65b4-11d1-a3da-0000f875ae0d": "Add-GUID",
 	"1abd7cf8-0a99-11d1-adbb-00c04fd8d5cd": "Allocate-Rids",
@@ -141,9 +246,13 @@ var GUIDS = map[string]string{
 	"00299570-246d-11d0-a768-00aa006e0529": "User-Force-Change-Password",
 	"5f202010-79a5-11d0-9020-00c04fc2d4cf": "User-Logon",
 	"e45795b3-9455-11d1-aebd-0000f80367c1": "Web-Information",
+}
 
-	// AD
-	// https://docs.microsoft.com/en-us/windows/win32/adschema/attributes-all
+// AttributeGUIDs maps the schemaIDGUID of the default attributeSchema
+// objects to their names
+//
+// https://docs.microsoft.com/en-us/windows/win32/adschema/attributes-all
+var AttributeGUIDs = map[string]string{
 	"bf967915-0de6-11d0-a285-00aa003049e2": "Account-Expires",
 	"031952ec-3b72-11d2-90cc-00c04fd91ab1": "Account-Name-History",
 	"7f56127d-5301-11d1-a9c5-0000f80367c1": "ACS-Aggregate-Token-Rate-Per-User",
@@ -1643,9 +1752,13 @@ var GUIDS = map[string]string{
 	"bf967a7b-0de6-11d0-a285-00aa003049e2": "X121-Address",
 	"d07da11f-8a3d-42b6-b0aa-76c962be719a": "x500uniqueIdentifier",
 	"bf967a7f-0de6-11d0-a285-00aa003049e2": "X509-Cert",
+}
 
-	// Classes
-	// https://docs.microsoft.com/ru-ru/openspecs/windows_protocols/ms-adsc/9abb5e97-123d-4da9-9557-b353ab79b830
+// ClassGUIDs maps the schemaIDGUID of the default classSchema objects
+// to their names
+//
+// https://docs.microsoft.com/ru-ru/openspecs/windows_protocols/ms-adsc/9abb5e97-123d-4da9-9557-b353ab79b830
+var ClassGUIDs = map[string]string{
 	"2628a46a-a6ad-4ae0-b854-2b12d9fe6f9e": "account",
 	"7f561288-5301-11d1-a9c5-0000f80367c1": "ACS-Policy",
 	"2e899b04-2834-11d3-91d4-0000f87a57d4": "ACS-Resource-Limits",
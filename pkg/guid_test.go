@@ -2,6 +2,7 @@ package winacl_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -21,3 +22,130 @@ func TestNewGUID(t *testing.T) {
 	})
 
 }
+
+func TestParseGUID(t *testing.T) {
+
+	r := require.New(t)
+
+	t.Run("Parses the string form of a GUID", func(t *testing.T) {
+		guid, err := winacl.ParseGUID("00299570-246d-11d0-a768-00aa006e0529")
+		r.NoError(err)
+		r.Equal("00299570-246d-11d0-a768-00aa006e0529", guid.String())
+
+		upper, err := winacl.ParseGUID("00299570-246D-11D0-A768-00AA006E0529")
+		r.NoError(err)
+		r.Equal(guid, upper)
+	})
+
+	t.Run("Returns an error when given a malformed GUID", func(t *testing.T) {
+		_, err := winacl.ParseGUID("00299570-246d-11d0-a768")
+		r.IsType(winacl.GUIDInvalidError{}, err)
+
+		_, err = winacl.ParseGUID("0029957g-246d-11d0-a768-00aa006e0529")
+		r.IsType(winacl.GUIDInvalidError{}, err)
+	})
+
+}
+
+func TestLookupGUIDByName(t *testing.T) {
+
+	r := require.New(t)
+
+	t.Run("Finds built-in GUIDs case-insensitively", func(t *testing.T) {
+		info, err := winacl.LookupGUIDByName("user-force-change-password")
+		r.NoError(err)
+		r.Equal("00299570-246d-11d0-a768-00aa006e0529", info.GUID.String())
+		r.Equal(winacl.GUIDKindExtendedRight, info.Kind)
+	})
+
+	t.Run("Finds GUIDs loaded from a schema by cn or lDAPDisplayName", func(t *testing.T) {
+		resolver, err := newTestGUIDResolver()
+		r.NoError(err)
+
+		laps, err := resolver.LookupName("ms-mcs-admpwd")
+		r.NoError(err)
+		r.Equal("a8e5f3c2-6d1b-4e4f-9a0b-2c5d7e8f9a10", laps.GUID.String())
+
+		byCN, err := resolver.LookupName("corp-Badge-Number")
+		r.NoError(err)
+		byLDAPName, err := resolver.LookupName("corpBadgeNumber")
+		r.NoError(err)
+		r.Equal(byCN, byLDAPName)
+
+		_, err = winacl.LookupGUIDByName("ms-Mcs-AdmPwd")
+		r.IsType(winacl.GUIDNameNotFoundError{}, err)
+	})
+
+	t.Run("Reports names shared by several GUIDs", func(t *testing.T) {
+		resolver := winacl.NewGUIDResolver()
+		other, err := winacl.ParseGUID("11111111-2222-3333-4444-555555555555")
+		r.NoError(err)
+		resolver.AddAlias(other, "User")
+
+		_, err = resolver.LookupName("user")
+		r.IsType(winacl.GUIDNameAmbiguousError{}, err)
+		r.Len(err.(winacl.GUIDNameAmbiguousError).Matches, 2)
+	})
+
+}
+
+func TestGUIDEncoding(t *testing.T) {
+
+	r := require.New(t)
+
+	t.Run("Parses braced and unbraced GUIDs", func(t *testing.T) {
+		braced, err := winacl.ParseGUID("{00299570-246d-11d0-a768-00aa006e0529}")
+		r.NoError(err)
+		unbraced, err := winacl.ParseGUID("00299570-246d-11d0-a768-00aa006e0529")
+		r.NoError(err)
+		r.Equal(unbraced, braced)
+
+		_, err = winacl.ParseGUID("{00299570-246d-11d0-a768-00aa006e0529")
+		r.Error(err)
+	})
+
+	t.Run("Round trips through the mixed-endian wire format", func(t *testing.T) {
+		guid, err := winacl.ParseGUID("00299570-246d-11d0-a768-00aa006e0529")
+		r.NoError(err)
+
+		raw, err := guid.MarshalBinary()
+		r.NoError(err)
+		r.Equal([]byte{
+			0x70, 0x95, 0x29, 0x00, 0x6d, 0x24, 0xd0, 0x11,
+			0xa7, 0x68, 0x00, 0xaa, 0x00, 0x6e, 0x05, 0x29,
+		}, raw)
+
+		var decoded winacl.GUID
+		r.NoError(decoded.UnmarshalBinary(raw))
+		r.Equal(guid, decoded)
+		r.Error(decoded.UnmarshalBinary(raw[:8]))
+	})
+
+	t.Run("Marshals the null GUID explicitly", func(t *testing.T) {
+		var null winacl.GUID
+		r.True(null.IsNull())
+		r.Equal("", null.String())
+
+		text, err := null.MarshalText()
+		r.NoError(err)
+		r.Equal("00000000-0000-0000-0000-000000000000", string(text))
+
+		var decoded winacl.GUID
+		r.NoError(decoded.UnmarshalText(text))
+		r.True(decoded.IsNull())
+	})
+
+	t.Run("Marshals to and from JSON strings", func(t *testing.T) {
+		guid, err := winacl.ParseGUID("00299570-246d-11d0-a768-00aa006e0529")
+		r.NoError(err)
+
+		encoded, err := json.Marshal(guid)
+		r.NoError(err)
+		r.Equal(`"00299570-246d-11d0-a768-00aa006e0529"`, string(encoded))
+
+		var decoded winacl.GUID
+		r.NoError(json.Unmarshal([]byte(`"{00299570-246d-11d0-a768-00aa006e0529}"`), &decoded))
+		r.Equal(guid, decoded)
+	})
+
+}
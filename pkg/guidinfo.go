@@ -0,0 +1,168 @@
+package winacl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GUIDKind identifies what an object type GUID refers to
+type GUIDKind byte
+
+const (
+	GUIDKindUnknown GUIDKind = iota
+	GUIDKindClass
+	GUIDKindAttribute
+	GUIDKindPropertySet
+	GUIDKindExtendedRight
+	GUIDKindValidatedWrite
+)
+
+// GUIDKindLookup maps GUIDKinds to a human-readable labels
+var GUIDKindLookup = map[GUIDKind]string{
+	GUIDKindUnknown:        "unknown",
+	GUIDKindClass:          "class",
+	GUIDKindAttribute:      "attribute",
+	GUIDKindPropertySet:    "property set",
+	GUIDKindExtendedRight:  "extended right",
+	GUIDKindValidatedWrite: "validated write",
+}
+
+// String returns a GUIDKind's human-readable label
+func (k GUIDKind) String() string {
+	return GUIDKindLookup[k]
+}
+
+// GUIDInfo describes a resolved object type GUID. ValidAccesses holds
+// the object-specific rights that are meaningful in an object ACE
+// carrying the GUID as its ObjectType
+type GUIDInfo struct {
+	GUID          GUID
+	Name          string
+	Kind          GUIDKind
+	ValidAccesses uint32
+}
+
+// String returns the kind and name of the GUID, e.g. "attribute member"
+func (info GUIDInfo) String() string {
+	return fmt.Sprintf("%s %s", info.Kind, info.Name)
+}
+
+const (
+	// objectSpecificRights are the rights an ObjectType GUID can scope
+	objectSpecificRights = ADSRightDSCreateChild | ADSRightDSDeleteChild |
+		ADSRightDSSelf | ADSRightDSReadProp | ADSRightDSWriteProp |
+		ADSRightDSControlAccess
+
+	classValidAccesses         = ADSRightDSCreateChild | ADSRightDSDeleteChild
+	attributeValidAccesses     = ADSRightDSReadProp | ADSRightDSWriteProp
+	propertySetValidAccesses   = ADSRightDSReadProp | ADSRightDSWriteProp
+	extendedRightValidAccesses = ADSRightDSControlAccess
+)
+
+// PropertySetGUIDs maps the entries of ControlAccessRightGUIDs that
+// are property sets rather than extended rights to their validAccesses
+var PropertySetGUIDs = map[string]uint32{
+	"b8119fd0-04f6-4762-ab7a-4986c76b3f9a": 48,  // Domain-Other-Parameters
+	"c7407360-20bf-11d0-a768-00aa006e0529": 48,  // Domain-Password
+	"e45795b2-9455-11d1-aebd-0000f80367c1": 48,  // Email-Information
+	"59ba2f42-79a2-11d0-9020-00c04fc2d3cf": 48,  // General-Information
+	"bc0ac240-79a9-11d0-9020-00c04fc2d4cf": 48,  // Membership
+	"77b5b886-944a-11d1-aebd-0000f80367c1": 48,  // Personal-Information
+	"91e647de-d96f-4b70-9557-d63ff4f3ccd8": 304, // Private-Information
+	"e48d0154-bcf8-11d1-8702-00c04fb96050": 48,  // Public-Information
+	"037088f8-0ae1-11d2-b422-00a0c968f939": 48,  // RAS-Information
+	"5805bc62-bdc9-4428-a5e2-856a0f4c185e": 48,  // Terminal-Server-License-Server
+	"4c164200-20c0-11d0-a768-00aa006e0529": 48,  // User-Account-Restrictions
+	"5f202010-79a5-11d0-9020-00c04fc2d4cf": 48,  // User-Logon
+	"e45795b3-9455-11d1-aebd-0000f80367c1": 48,  // Web-Information
+}
+
+// ValidatedWriteGUIDs maps the rightsGuid of validated writes to their
+// validAccesses. Most are the schemaIDGUID of the attribute they guard,
+// and keep that attribute's name
+var ValidatedWriteGUIDs = map[string]uint32{
+	"bf9679c0-0de6-11d0-a285-00aa003049e2": 8, // Self-Membership
+	"9b026da6-0d3c-465c-8bee-5199d7165cba": 8, // DS-Validated-Write-Computer
+	"f3a64788-5306-11d1-a9c5-0000f80367c1": 8, // Validated-SPN
+	"72e39547-7b18-11d1-adef-00c04fd8d5cd": 8, // Validated-DNS-Host-Name
+	"d31a8757-2447-4545-8081-3bb610cacbf2": 8, // Validated-MS-DS-Behavior-Version
+	"80863791-dbe9-4eb8-837e-7f0ab55d9ac7": 8, // Validated-MS-DS-Additional-DNS-Host-Name
+}
+
+// builtinGUIDInfo holds the typed view of the built-in GUID tables
+var builtinGUIDInfo = newBuiltinGUIDInfo()
+
+func newBuiltinGUIDInfo() map[string]GUIDInfo {
+	infos := make(map[string]GUIDInfo, len(GUIDS))
+
+	add := func(table map[string]string, kind GUIDKind, validAccesses uint32) {
+		for guidStr, name := range table {
+			guid, err := ParseGUID(guidStr)
+			if err != nil {
+				continue
+			}
+			infos[guidStr] = GUIDInfo{
+				GUID:          guid,
+				Name:          name,
+				Kind:          kind,
+				ValidAccesses: validAccesses,
+			}
+		}
+	}
+
+	add(ClassGUIDs, GUIDKindClass, classValidAccesses)
+	add(AttributeGUIDs, GUIDKindAttribute, attributeValidAccesses)
+	add(ControlAccessRightGUIDs, GUIDKindExtendedRight, extendedRightValidAccesses)
+
+	for guidStr, validAccesses := range PropertySetGUIDs {
+		info := infos[guidStr]
+		info.Kind = GUIDKindPropertySet
+		info.ValidAccesses = validAccesses
+		infos[guidStr] = info
+	}
+
+	// Validated writes share their GUID with the attribute they guard,
+	// so property rights remain valid alongside SELF
+	for guidStr, validAccesses := range ValidatedWriteGUIDs {
+		info := infos[guidStr]
+		info.Kind = GUIDKindValidatedWrite
+		info.ValidAccesses = validAccesses | attributeValidAccesses
+		infos[guidStr] = info
+	}
+
+	return infos
+}
+
+// builtinGUIDNames maps the lowercased names of the built-in tables to
+// their GUIDs
+var builtinGUIDNames = newBuiltinGUIDNames()
+
+func newBuiltinGUIDNames() map[string][]string {
+	names := make(map[string][]string, len(GUIDS))
+	for guid, name := range GUIDS {
+		key := strings.ToLower(name)
+		names[key] = append(names[key], guid)
+	}
+	for guid, name := range sharedAttributeNames {
+		key := strings.ToLower(name)
+		names[key] = append(names[key], guid)
+	}
+	return names
+}
+
+// Lookup returns the typed description of a GUID from the
+// built-in tables
+func (g GUID) Lookup() (GUIDInfo, bool) {
+	info, found := builtinGUIDInfo[g.String()]
+	return info, found
+}
+
+func mergeGUIDTables(tables ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, table := range tables {
+		for guid, name := range table {
+			merged[guid] = name
+		}
+	}
+	return merged
+}
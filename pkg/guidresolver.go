@@ -0,0 +1,318 @@
+package winacl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GUIDResolver resolves schema and control access right GUIDs to their
+// names. Entries loaded from a directory's schema take precedence over
+// the built-in GUIDS table, which is used as a fallback. A nil
+// *GUIDResolver only consults the built-in table and is read-only: Add,
+// AddAlias and LoadSchemaLDIF need one returned by NewGUIDResolver.
+type GUIDResolver struct {
+	entries       map[string]GUIDInfo
+	aliases       map[string]map[string]bool
+	propertySetOf map[string]string
+	classes       map[string]schemaClass
+}
+
+// NewGUIDResolver returns a GUIDResolver backed only by the built-in table
+func NewGUIDResolver() *GUIDResolver {
+	return &GUIDResolver{
+		entries:       make(map[string]GUIDInfo),
+		aliases:       make(map[string]map[string]bool),
+		propertySetOf: make(map[string]string),
+		classes:       make(map[string]schemaClass),
+	}
+}
+
+// Add registers a GUID, overriding the built-in table. When the GUID is
+// already known as a control access right and info describes the
+// attribute sharing its GUID (as validated writes do), the existing kind
+// is kept and the valid accesses are combined. It panics on a nil
+// resolver
+func (r *GUIDResolver) Add(info GUIDInfo) {
+	r.AddAlias(info.GUID, info.Name)
+
+	if existing, found := r.Lookup(info.GUID); found {
+		switch {
+		case existing.Kind == GUIDKindValidatedWrite && info.Kind == GUIDKindAttribute:
+			existing.ValidAccesses |= info.ValidAccesses
+			info = existing
+		case existing.Kind == GUIDKindAttribute && info.Kind == GUIDKindValidatedWrite:
+			info.ValidAccesses |= existing.ValidAccesses
+		}
+	}
+	r.entries[info.GUID.String()] = info
+}
+
+// AddAlias registers an additional name under which LookupName
+// finds a GUID, such as an attribute's cn alongside its lDAPDisplayName.
+// It panics on a nil resolver
+func (r *GUIDResolver) AddAlias(guid GUID, name string) {
+	if name == "" {
+		return
+	}
+
+	key := strings.ToLower(name)
+	if r.aliases[key] == nil {
+		r.aliases[key] = make(map[string]bool)
+	}
+	r.aliases[key][guid.String()] = true
+}
+
+// LookupName returns the GUID registered under a name, searching the
+// loaded schema and the built-in tables case-insensitively. A
+// GUIDNameAmbiguousError is returned when the name refers to more than
+// one GUID
+func (r *GUIDResolver) LookupName(name string) (GUIDInfo, error) {
+	key := strings.ToLower(name)
+	candidates := make(map[string]bool)
+
+	for _, guid := range builtinGUIDNames[key] {
+		candidates[guid] = true
+	}
+	if r != nil {
+		for guid := range r.aliases[key] {
+			candidates[guid] = true
+		}
+	}
+
+	matches := []GUIDInfo{}
+	for guidStr := range candidates {
+		guid, err := ParseGUID(guidStr)
+		if err != nil {
+			continue
+		}
+		if info, found := r.Lookup(guid); found {
+			matches = append(matches, info)
+		} else {
+			matches = append(matches, GUIDInfo{GUID: guid, Name: name})
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return GUIDInfo{}, GUIDNameNotFoundError{name}
+	case 1:
+		return matches[0], nil
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].GUID.String() < matches[j].GUID.String()
+	})
+	return GUIDInfo{}, GUIDNameAmbiguousError{Name: name, Matches: matches}
+}
+
+// Lookup returns the typed description of a GUID
+func (r *GUIDResolver) Lookup(guid GUID) (GUIDInfo, bool) {
+	if r != nil {
+		if info, found := r.entries[guid.String()]; found {
+			return info, true
+		}
+	}
+	return guid.Lookup()
+}
+
+// Resolve returns the name of a GUID. If the GUID is not resolvable,
+// the GUID string will be returned instead
+func (r *GUIDResolver) Resolve(guid GUID) string {
+	if info, found := r.Lookup(guid); found {
+		return info.Name
+	}
+	return guid.String()
+}
+
+// ValidAccesses returns the object-specific rights that are meaningful
+// for a GUID
+func (r *GUIDResolver) ValidAccesses(guid GUID) (uint32, bool) {
+	info, found := r.Lookup(guid)
+	return info.ValidAccesses, found
+}
+
+// ValidateACE checks that the rights granted by an object ACE fit the
+// kind of its ObjectType, e.g. that an extended right is used with
+// CONTROL_ACCESS rather than WRITE_PROP, and that its
+// InheritedObjectType names a class. SELF is accepted on attributes whose
+// GUID doubles as a validated write. Unknown GUIDs are not reported
+func (r *GUIDResolver) ValidateACE(ace ACE) error {
+	aa, ok := ace.ObjectAce.(AdvancedAce)
+	if !ok {
+		return nil
+	}
+
+	if aa.Flags&ACEInheritanceFlagsObjectTypePresent != 0 {
+		info, found := r.Lookup(aa.ObjectType)
+		valid := info.ValidAccesses
+		if validatedWrite, isValidatedWrite := ValidatedWriteGUIDs[aa.ObjectType.String()]; isValidatedWrite {
+			valid |= validatedWrite
+		}
+
+		rights := ace.AccessMask.Raw() & objectSpecificRights
+		if found && rights&^valid != 0 {
+			invalid := ACEAccessMask{rights &^ valid}
+			return ObjectTypeMismatchError{fmt.Sprintf(
+				"%s is not valid for ObjectType %s", invalid, info)}
+		}
+	}
+
+	if aa.Flags&ACEInheritanceFlagsInheritedObjectTypePresent != 0 {
+		info, found := r.Lookup(aa.InheritedObjectType)
+		if found && info.Kind != GUIDKindClass {
+			return ObjectTypeMismatchError{fmt.Sprintf(
+				"InheritedObjectType %s is not a class", info)}
+		}
+	}
+
+	return nil
+}
+
+type ObjectTypeMismatchError struct{ msg string }
+
+func (e ObjectTypeMismatchError) Error() string {
+	return fmt.Sprintf("ValidateACE: %s", e.msg)
+}
+
+type GUIDNameNotFoundError struct{ Name string }
+
+func (e GUIDNameNotFoundError) Error() string {
+	return fmt.Sprintf("LookupName: no GUID named %q", e.Name)
+}
+
+type GUIDNameAmbiguousError struct {
+	Name    string
+	Matches []GUIDInfo
+}
+
+func (e GUIDNameAmbiguousError) Error() string {
+	guids := make([]string, 0, len(e.Matches))
+	for _, match := range e.Matches {
+		guids = append(guids, match.GUID.String())
+	}
+	return fmt.Sprintf("LookupName: %q is ambiguous between %s",
+		e.Name, strings.Join(guids, ", "))
+}
+
+// LoadSchemaLDIF populates the resolver from an LDIF export of
+// CN=Schema (attributeSchema and classSchema entries) and/or
+// CN=Extended-Rights (controlAccessRight entries), such as:
+//
+//	ldapsearch -b CN=Schema,CN=Configuration,DC=corp,DC=local \
+//		objectClass cn schemaIDGUID attributeSecurityGUID lDAPDisplayName subClassOf
+//	ldapsearch -b CN=Extended-Rights,CN=Configuration,DC=corp,DC=local \
+//		objectClass cn rightsGuid validAccesses
+//
+// Classes are told apart from attributes by their objectClass, without
+// which every schema entry loads as an attribute. The subClassOf of a
+// class lets ACEs scoped to its superclasses match it. Entries of any other
+// object class are ignored. A nil resolver returns an error.
+func (r *GUIDResolver) LoadSchemaLDIF(rdr io.Reader) error {
+	if r == nil {
+		return fmt.Errorf("LoadSchemaLDIF: the resolver is nil; use NewGUIDResolver")
+	}
+	lr := newLDIFReader(rdr)
+
+	for {
+		record, err := lr.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case record.HasValue("objectClass", "controlAccessRight") || record.Value("rightsGuid") != nil:
+			err = r.loadControlAccessRight(record)
+		case record.Value("schemaIDGUID") != nil:
+			err = r.loadSchemaObject(record)
+		}
+		if err != nil {
+			return fmt.Errorf("LoadSchemaLDIF: %s: %w", record.DN, err)
+		}
+	}
+}
+
+func (r *GUIDResolver) loadSchemaObject(record ldifRecord) error {
+	guid, err := NewGUID(bytes.NewBuffer(record.Value("schemaIDGUID")))
+	if err != nil {
+		return err
+	}
+
+	info := GUIDInfo{
+		GUID: guid,
+		Name: record.StringValue("lDAPDisplayName"),
+		Kind: GUIDKindAttribute,
+	}
+	if info.Name == "" {
+		info.Name = record.StringValue("cn")
+	}
+	if info.Name == "" {
+		return nil
+	}
+	r.AddAlias(guid, record.StringValue("cn"))
+
+	if record.HasValue("objectClass", "classSchema") {
+		info.Kind = GUIDKindClass
+		info.ValidAccesses = classValidAccesses
+		if superclass := record.StringValue("subClassOf"); superclass != "" {
+			r.classes[strings.ToLower(info.Name)] = schemaClass{
+				guid:       guid.String(),
+				superclass: strings.ToLower(superclass),
+			}
+		}
+	} else {
+		info.ValidAccesses = attributeValidAccesses
+	}
+	r.Add(info)
+
+	if raw := record.Value("attributeSecurityGUID"); raw != nil {
+		set, err := NewGUID(bytes.NewBuffer(raw))
+		if err != nil {
+			return err
+		}
+		r.propertySetOf[guid.String()] = set.String()
+	}
+	return nil
+}
+
+func (r *GUIDResolver) loadControlAccessRight(record ldifRecord) error {
+	guid, err := ParseGUID(record.StringValue("rightsGuid"))
+	if err != nil {
+		return err
+	}
+
+	info := GUIDInfo{
+		GUID:          guid,
+		Name:          record.StringValue("cn"),
+		Kind:          GUIDKindExtendedRight,
+		ValidAccesses: extendedRightValidAccesses,
+	}
+	if info.Name == "" {
+		info.Name = record.StringValue("displayName")
+	}
+	r.AddAlias(guid, record.StringValue("displayName"))
+
+	if raw := record.StringValue("validAccesses"); raw != "" {
+		mask, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid validAccesses %q", raw)
+		}
+		info.ValidAccesses = uint32(mask)
+	}
+
+	switch {
+	case info.ValidAccesses&propertySetValidAccesses != 0:
+		info.Kind = GUIDKindPropertySet
+	case info.ValidAccesses&ADSRightDSSelf != 0:
+		info.Kind = GUIDKindValidatedWrite
+	}
+
+	r.Add(info)
+	return nil
+}
@@ -0,0 +1,172 @@
+package winacl_test
+
+import (
+	"strings"
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGUIDResolver(t *testing.T) {
+
+	r := require.New(t)
+
+	lapsGUID := winacl.GUID{
+		Data1: 0xa8e5f3c2, Data2: 0x6d1b, Data3: 0x4e4f,
+		Data4: [8]byte{0x9a, 0x0b, 0x2c, 0x5d, 0x7e, 0x8f, 0x9a, 0x10},
+	}
+	userGUID := winacl.GUID{
+		Data1: 0xbf967aba, Data2: 0x0de6, Data3: 0x11d0,
+		Data4: [8]byte{0xa2, 0x85, 0x00, 0xaa, 0x00, 0x30, 0x49, 0xe2},
+	}
+
+	t.Run("Resolves GUIDs loaded from a schema LDIF export", func(t *testing.T) {
+		resolver, err := newTestGUIDResolver()
+		r.NoError(err)
+		r.Equal("ms-Mcs-AdmPwd", resolver.Resolve(lapsGUID))
+	})
+
+	t.Run("Falls back to the built-in table", func(t *testing.T) {
+		resolver, err := newTestGUIDResolver()
+		r.NoError(err)
+		r.Equal("User", resolver.Resolve(userGUID))
+
+		var nilResolver *winacl.GUIDResolver
+		r.Equal("User", nilResolver.Resolve(userGUID))
+		r.Equal(lapsGUID.String(), nilResolver.Resolve(lapsGUID))
+	})
+
+	t.Run("Loads validAccesses for control access rights", func(t *testing.T) {
+		resolver, err := newTestGUIDResolver()
+		r.NoError(err)
+
+		approveWidget := winacl.GUID{
+			Data1: 0x4b1e6f0a, Data2: 0x2c3d, Data3: 0x4e5f,
+			Data4: [8]byte{0x8a, 0x9b, 0x0c, 0x1d, 0x2e, 0x3f, 0x4a, 0x5b},
+		}
+		r.Equal("corp-Approve-Widget", resolver.Resolve(approveWidget))
+
+		mask, found := resolver.ValidAccesses(approveWidget)
+		r.True(found)
+		r.Equal(uint32(winacl.ADSRightDSControlAccess), mask)
+	})
+
+	t.Run("Returns an error when given a malformed LDIF stream", func(t *testing.T) {
+		resolver := winacl.NewGUIDResolver()
+		err := resolver.LoadSchemaLDIF(strings.NewReader("dn: CN=x\nschemaIDGUID:: !!!\n"))
+		r.Error(err)
+
+		var readOnly *winacl.GUIDResolver
+		r.Error(readOnly.LoadSchemaLDIF(strings.NewReader("")))
+	})
+
+	t.Run("Loads classes only when objectClass is exported", func(t *testing.T) {
+		entry := "dn: CN=corp-Widget,CN=Schema,CN=Configuration,DC=corp,DC=local\n" +
+			"lDAPDisplayName: corpWidget\nschemaIDGUID:: wvPlqBttT06aCyxdfo+aEA==\n"
+
+		resolver := winacl.NewGUIDResolver()
+		r.NoError(resolver.LoadSchemaLDIF(strings.NewReader(entry)))
+		info, _ := resolver.Lookup(lapsGUID)
+		r.Equal(winacl.GUIDKindAttribute, info.Kind)
+
+		resolver = winacl.NewGUIDResolver()
+		r.NoError(resolver.LoadSchemaLDIF(strings.NewReader(entry + "objectClass: top\nobjectClass: classSchema\n")))
+		info, _ = resolver.Lookup(lapsGUID)
+		r.Equal(winacl.GUIDKindClass, info.Kind)
+	})
+
+}
+
+func TestGUIDResolverLookup(t *testing.T) {
+
+	r := require.New(t)
+
+	forceChangePassword := winacl.GUID{
+		Data1: 0x00299570, Data2: 0x246d, Data3: 0x11d0,
+		Data4: [8]byte{0xa7, 0x68, 0x00, 0xaa, 0x00, 0x6e, 0x05, 0x29},
+	}
+	personalInformation := winacl.GUID{
+		Data1: 0x77b5b886, Data2: 0x944a, Data3: 0x11d1,
+		Data4: [8]byte{0xae, 0xbd, 0x00, 0x00, 0xf8, 0x03, 0x67, 0xc1},
+	}
+
+	t.Run("Distinguishes built-in extended rights and property sets", func(t *testing.T) {
+		info, found := forceChangePassword.Lookup()
+		r.True(found)
+		r.Equal(winacl.GUIDKindExtendedRight, info.Kind)
+		r.Equal("extended right User-Force-Change-Password", info.String())
+
+		info, found = personalInformation.Lookup()
+		r.True(found)
+		r.Equal(winacl.GUIDKindPropertySet, info.Kind)
+	})
+
+	t.Run("Types loaded schema objects and control access rights", func(t *testing.T) {
+		resolver, err := newTestGUIDResolver()
+		r.NoError(err)
+
+		widget := winacl.GUID{
+			Data1: 0x7e2f9a1c, Data2: 0x3b4d, Data3: 0x4f5e,
+			Data4: [8]byte{0xa6, 0xb7, 0xc8, 0xd9, 0xe0, 0xf1, 0xa2, 0xb3},
+		}
+		info, found := resolver.Lookup(widget)
+		r.True(found)
+		r.Equal(winacl.GUIDKindClass, info.Kind)
+
+		validatedWrite := winacl.GUID{
+			Data1: 0x9c8b7a6d, Data2: 0x5e4f, Data3: 0x4a3b,
+			Data4: [8]byte{0x8c, 0x2d, 0x1e, 0x0f, 0x9a, 0x8b, 0x7c, 0x6d},
+		}
+		info, found = resolver.Lookup(validatedWrite)
+		r.True(found)
+		r.Equal(winacl.GUIDKindValidatedWrite, info.Kind)
+	})
+
+	t.Run("Accepts SELF on attributes that double as validated writes", func(t *testing.T) {
+		// (OA;;SW;f3a64788-5306-11d1-a9c5-0000f80367c1;;PS) from the default computer DACL
+		spn, err := winacl.ParseGUID("f3a64788-5306-11d1-a9c5-0000f80367c1")
+		r.NoError(err)
+		self, err := winacl.ParseSID("S-1-5-10")
+		r.NoError(err)
+		ace := winacl.BuildObjectAce(winacl.AceTypeAccessAllowedObject, 0, winacl.ADSRightDSSelf, self, spn, winacl.GUID{})
+
+		var builtin *winacl.GUIDResolver
+		r.NoError(builtin.ValidateACE(ace))
+
+		resolver, err := newTestGUIDResolver()
+		r.NoError(err)
+		r.NoError(resolver.ValidateACE(ace))
+
+		for _, guidStr := range []string{
+			"72e39547-7b18-11d1-adef-00c04fd8d5cd",
+			"d31a8757-2447-4545-8081-3bb610cacbf2",
+			"80863791-dbe9-4eb8-837e-7f0ab55d9ac7",
+		} {
+			guid, err := winacl.ParseGUID(guidStr)
+			r.NoError(err)
+			info, found := builtin.Lookup(guid)
+			r.True(found)
+			r.Equal(winacl.GUIDKindValidatedWrite, info.Kind)
+			r.NoError(builtin.ValidateACE(winacl.BuildObjectAce(winacl.AceTypeAccessAllowedObject, 0, winacl.ADSRightDSSelf, self, guid, winacl.GUID{})))
+		}
+	})
+
+	t.Run("Flags ACEs whose rights don't fit the ObjectType", func(t *testing.T) {
+		ace, err := newTestObjectACE(winacl.ADSRightDSWriteProp, forceChangePassword)
+		r.NoError(err)
+		r.IsType(winacl.ObjectTypeMismatchError{}, winacl.NewGUIDResolver().ValidateACE(ace))
+
+		ace, err = newTestObjectACE(winacl.ADSRightDSControlAccess, forceChangePassword)
+		r.NoError(err)
+		r.NoError(winacl.NewGUIDResolver().ValidateACE(ace))
+	})
+
+	t.Run("Accepts the ACEs of a parsed Security Descriptor", func(t *testing.T) {
+		var resolver *winacl.GUIDResolver
+		for _, ace := range newTestSD().DACL.Aces {
+			r.NoError(resolver.ValidateACE(ace))
+		}
+	})
+
+}
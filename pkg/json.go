@@ -0,0 +1,319 @@
+package winacl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/audibleblink/bamflags"
+)
+
+// ACE kind discriminators used in the JSON encoding of an ACE
+const (
+	ACEKindBasic       = "basic"
+	ACEKindObject      = "object"
+	ACEKindUnsupported = "unsupported"
+)
+
+type sidJSON struct {
+	SID  string `json:"sid"`
+	Name string `json:"name,omitempty"`
+}
+
+type accessMaskJSON struct {
+	Value  uint32   `json:"value"`
+	Rights []string `json:"rights"`
+}
+
+type aceJSON struct {
+	Kind                    string        `json:"kind"`
+	Type                    AceType       `json:"type"`
+	TypeName                string        `json:"typeName"`
+	Flags                   byte          `json:"flags"`
+	FlagNames               []string      `json:"flagNames"`
+	Size                    uint16        `json:"size"`
+	Mask                    ACEAccessMask `json:"mask"`
+	ObjectFlags             *uint32       `json:"objectFlags,omitempty"`
+	ObjectFlagNames         []string      `json:"objectFlagNames,omitempty"`
+	ObjectType              *GUID         `json:"objectType,omitempty"`
+	ObjectTypeName          string        `json:"objectTypeName,omitempty"`
+	InheritedObjectType     *GUID         `json:"inheritedObjectType,omitempty"`
+	InheritedObjectTypeName string        `json:"inheritedObjectTypeName,omitempty"`
+	Principal               *SID          `json:"principal,omitempty"`
+}
+
+type aclJSON struct {
+	Revision byte   `json:"revision"`
+	Sbz1     byte   `json:"sbz1"`
+	Size     uint16 `json:"size"`
+	AceCount uint16 `json:"aceCount"`
+	Sbz2     uint16 `json:"sbz2"`
+	Aces     []ACE  `json:"aces"`
+}
+
+type ntsdHeaderJSON struct {
+	Revision    byte         `json:"revision"`
+	Sbz1        byte         `json:"sbz1"`
+	Control     ControlFlags `json:"control"`
+	OffsetOwner uint32       `json:"offsetOwner"`
+	OffsetGroup uint32       `json:"offsetGroup"`
+	OffsetSacl  uint32       `json:"offsetSacl"`
+	OffsetDacl  uint32       `json:"offsetDacl"`
+}
+
+type ntsdJSON struct {
+	Header ntsdHeaderJSON `json:"header"`
+	Owner  SID            `json:"owner"`
+	Group  SID            `json:"group"`
+	DACL   *ACL           `json:"dacl,omitempty"`
+	SACL   *ACL           `json:"sacl,omitempty"`
+}
+
+// MarshalJSON encodes a SID as an object holding its string form
+// and, when it is well known, its resolved name
+func (s SID) MarshalJSON() ([]byte, error) {
+	sidStr := s.String()
+	if sidStr == "" {
+		return []byte("null"), nil
+	}
+
+	encoded := sidJSON{SID: sidStr}
+	if resolved := s.Resolve(); resolved != sidStr {
+		encoded.Name = resolved
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON decodes a SID from either its object encoding
+// or a bare SID string
+func (s *SID) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*s = SID{}
+		return nil
+	}
+
+	var decoded sidJSON
+	if err := json.Unmarshal(data, &decoded.SID); err != nil {
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return err
+		}
+	}
+
+	sid, err := ParseSID(decoded.SID)
+	if err != nil {
+		return err
+	}
+	*s = sid
+	return nil
+}
+
+// MarshalJSON encodes an ACEAccessMask as its raw value alongside
+// its human-readable rights
+func (am ACEAccessMask) MarshalJSON() ([]byte, error) {
+	rights := am.StringSlice()
+	if rights == nil {
+		rights = []string{}
+	}
+	return json.Marshal(accessMaskJSON{Value: am.value, Rights: rights})
+}
+
+// UnmarshalJSON decodes an ACEAccessMask from its object encoding
+// or a bare number. Only the raw value is used
+func (am *ACEAccessMask) UnmarshalJSON(data []byte) error {
+	var decoded accessMaskJSON
+	if err := json.Unmarshal(data, &decoded.Value); err != nil {
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return err
+		}
+	}
+	am.value = decoded.Value
+	return nil
+}
+
+// MarshalJSON encodes an ACE with its raw header and mask values,
+// their symbolic names, and the resolved principal and object types.
+// The kind field discriminates between basic and object ACEs
+func (s ACE) MarshalJSON() ([]byte, error) {
+	encoded := aceJSON{
+		Kind:     ACEKindUnsupported,
+		Type:     s.Header.Type,
+		TypeName: s.GetTypeString(),
+		Flags:    byte(s.Header.Flags),
+		FlagNames: flagNames(int64(s.Header.Flags), func(flag int) string {
+			return ACEHeaderFlagLookup[ACEHeaderFlags(flag)]
+		}),
+		Size: s.Header.Size,
+		Mask: s.AccessMask,
+	}
+
+	switch ace := s.ObjectAce.(type) {
+	case BasicAce:
+		encoded.Kind = ACEKindBasic
+		encoded.Principal = &ace.SecurityIdentifier
+
+	case AdvancedAce:
+		encoded.Kind = ACEKindObject
+		encoded.Principal = &ace.SecurityIdentifier
+
+		objectFlags := uint32(ace.Flags)
+		encoded.ObjectFlags = &objectFlags
+		encoded.ObjectFlagNames = flagNames(int64(ace.Flags), func(flag int) string {
+			return ACEInheritanceFlagsLookup[ACEInheritanceFlags(flag)]
+		})
+
+		if ace.Flags&ACEInheritanceFlagsObjectTypePresent != 0 {
+			encoded.ObjectType = &ace.ObjectType
+			encoded.ObjectTypeName = ace.ObjectType.Resolve()
+		}
+		if ace.Flags&ACEInheritanceFlagsInheritedObjectTypePresent != 0 {
+			encoded.InheritedObjectType = &ace.InheritedObjectType
+			encoded.InheritedObjectTypeName = ace.InheritedObjectType.Resolve()
+		}
+	}
+
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON decodes an ACE from its JSON encoding. Symbolic and
+// resolved names are ignored in favour of the raw values
+func (s *ACE) UnmarshalJSON(data []byte) error {
+	var decoded aceJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	ace := ACE{
+		Header: ACEHeader{
+			Type:  decoded.Type,
+			Flags: ACEHeaderFlags(decoded.Flags),
+			Size:  decoded.Size,
+		},
+		AccessMask: decoded.Mask,
+	}
+
+	var principal SID
+	if decoded.Principal != nil {
+		principal = *decoded.Principal
+	}
+
+	switch decoded.Kind {
+	case ACEKindBasic:
+		ace.ObjectAce = BasicAce{SecurityIdentifier: principal}
+
+	case ACEKindObject:
+		aa := AdvancedAce{SecurityIdentifier: principal}
+		if decoded.ObjectFlags != nil {
+			aa.Flags = ACEInheritanceFlags(*decoded.ObjectFlags)
+		}
+		if decoded.ObjectType != nil {
+			aa.ObjectType = *decoded.ObjectType
+		}
+		if decoded.InheritedObjectType != nil {
+			aa.InheritedObjectType = *decoded.InheritedObjectType
+		}
+		ace.ObjectAce = aa
+
+	case ACEKindUnsupported:
+
+	default:
+		return fmt.Errorf("ACE.UnmarshalJSON: unknown ACE kind %q", decoded.Kind)
+	}
+
+	*s = ace
+	return nil
+}
+
+// MarshalJSON encodes an ACL with its header values and ACEs
+func (a ACL) MarshalJSON() ([]byte, error) {
+	aces := a.Aces
+	if aces == nil {
+		aces = []ACE{}
+	}
+
+	return json.Marshal(aclJSON{
+		Revision: a.Header.Revision,
+		Sbz1:     a.Header.Sbz1,
+		Size:     a.Header.Size,
+		AceCount: a.Header.AceCount,
+		Sbz2:     a.Header.Sbz2,
+		Aces:     aces,
+	})
+}
+
+// UnmarshalJSON decodes an ACL from its JSON encoding
+func (a *ACL) UnmarshalJSON(data []byte) error {
+	var decoded aclJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	a.Header = ACLHeader{
+		Revision: decoded.Revision,
+		Sbz1:     decoded.Sbz1,
+		Size:     decoded.Size,
+		AceCount: decoded.AceCount,
+		Sbz2:     decoded.Sbz2,
+	}
+	a.Aces = decoded.Aces
+	if a.Aces == nil {
+		a.Aces = []ACE{}
+	}
+	return nil
+}
+
+// MarshalJSON encodes an NtSecurityDescriptor with its raw header,
+// owner, group and ACLs. ACLs that are not present, including a NULL DACL,
+// are omitted
+func (s NtSecurityDescriptor) MarshalJSON() ([]byte, error) {
+	encoded := ntsdJSON{
+		Header: ntsdHeaderJSON(s.Header),
+		Owner:  s.Owner,
+		Group:  s.Group,
+	}
+
+	if s.DACL != nil {
+		encoded.DACL = s.DACL
+	}
+	if s.SACL.isPresent() {
+		encoded.SACL = &s.SACL
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON decodes an NtSecurityDescriptor from its JSON encoding
+func (s *NtSecurityDescriptor) UnmarshalJSON(data []byte) error {
+	var decoded ntsdJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	ntsd := NtSecurityDescriptor{
+		Header: NtSecurityDescriptorHeader(decoded.Header),
+		Owner:  decoded.Owner,
+		Group:  decoded.Group,
+	}
+	ntsd.DACL = decoded.DACL
+	if decoded.SACL != nil {
+		ntsd.SACL = *decoded.SACL
+	}
+
+	*s = ntsd
+	return nil
+}
+
+// isPresent reports whether the ACL was parsed or built, as opposed
+// to being left zero-valued
+func (a ACL) isPresent() bool {
+	return a.Header.Revision != 0 || len(a.Aces) > 0
+}
+
+func flagNames(value int64, lookup func(flag int) string) []string {
+	names := []string{}
+	flags, _ := bamflags.ParseInt(value)
+	for _, flag := range flags {
+		if name := lookup(flag); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
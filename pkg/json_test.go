@@ -0,0 +1,85 @@
+package winacl_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNtSecurityDescriptorJSON(t *testing.T) {
+
+	r := require.New(t)
+
+	t.Run("Round trips a Security Descriptor through JSON", func(t *testing.T) {
+		sd := newTestSD()
+		encoded, err := json.Marshal(sd)
+		r.NoError(err)
+
+		var decoded winacl.NtSecurityDescriptor
+		r.NoError(json.Unmarshal(encoded, &decoded))
+		r.Equal(sd, decoded)
+		r.Equal(sd.ToSDDL(), decoded.ToSDDL())
+	})
+
+	t.Run("Keeps the reserved ACL header fields", func(t *testing.T) {
+		dacl := *newTestSD().DACL
+		dacl.Header.Sbz1, dacl.Header.Sbz2 = 1, 2
+		encoded, err := json.Marshal(dacl)
+		r.NoError(err)
+
+		var decoded winacl.ACL
+		r.NoError(json.Unmarshal(encoded, &decoded))
+		r.Equal(dacl.Header, decoded.Header)
+	})
+
+	t.Run("Includes raw values, symbolic names and resolved names", func(t *testing.T) {
+		encoded, err := json.Marshal(newTestSD().DACL.Aces[0])
+		r.NoError(err)
+
+		var ace map[string]interface{}
+		r.NoError(json.Unmarshal(encoded, &ace))
+		r.Equal(winacl.ACEKindObject, ace["kind"])
+		r.Equal("ACCESS_ALLOWED_OBJECT", ace["typeName"])
+		r.Equal("User-Account-Restrictions", ace["objectTypeName"])
+		r.Equal(map[string]interface{}{
+			"value":  float64(winacl.ADSRightDSReadProp),
+			"rights": []interface{}{"READ_PROP"},
+		}, ace["mask"])
+		r.Equal("RAS Servers", ace["principal"].(map[string]interface{})["name"])
+	})
+
+	t.Run("Omits ACLs that are not present", func(t *testing.T) {
+		encoded, err := json.Marshal(newTestSD())
+		r.NoError(err)
+
+		var sd map[string]interface{}
+		r.NoError(json.Unmarshal(encoded, &sd))
+		r.Contains(sd, "dacl")
+		r.NotContains(sd, "sacl")
+	})
+
+	t.Run("Returns an error when given an unknown ACE kind", func(t *testing.T) {
+		var ace winacl.ACE
+		r.Error(json.Unmarshal([]byte(`{"kind":"compound"}`), &ace))
+	})
+
+}
+
+func TestSIDJSON(t *testing.T) {
+
+	r := require.New(t)
+
+	t.Run("Decodes bare SID strings and SID objects", func(t *testing.T) {
+		var bare, object winacl.SID
+		r.NoError(json.Unmarshal([]byte(`"S-1-5-32-544"`), &bare))
+		r.NoError(json.Unmarshal([]byte(`{"sid":"S-1-5-32-544","name":"Built-in Administrators"}`), &object))
+		r.Equal(bare, object)
+
+		encoded, err := json.Marshal(bare)
+		r.NoError(err)
+		r.JSONEq(`{"sid":"S-1-5-32-544","name":"Built-in Administrators"}`, string(encoded))
+	})
+
+}
@@ -0,0 +1,254 @@
+package winacl
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DirectoryObject is a directory entry read from an LDIF export along
+// with its parsed nTSecurityDescriptor
+type DirectoryObject struct {
+	DN                 string
+	ObjectClass        []string
+	SecurityDescriptor NtSecurityDescriptor
+}
+
+// Class returns the most specific of the object's classes, which
+// directory servers list last, e.g. "computer" for a computer account
+func (o DirectoryObject) Class() string {
+	if len(o.ObjectClass) == 0 {
+		return ""
+	}
+	return o.ObjectClass[len(o.ObjectClass)-1]
+}
+
+// LDIFDescriptorReader reads the nTSecurityDescriptor attributes of the
+// entries in an LDIF export, such as the output of:
+//
+//	ldapsearch -E '!1.2.840.113556.1.4.801=::MAMCAQc=' -b DC=corp,DC=local \
+//		'(objectClass=*)' nTSecurityDescriptor objectClass
+//
+// Entries without an nTSecurityDescriptor are skipped.
+type LDIFDescriptorReader struct {
+	lr *ldifReader
+}
+
+// NewLDIFDescriptorReader is a constructor for an LDIFDescriptorReader
+// reading from rdr
+func NewLDIFDescriptorReader(rdr io.Reader) *LDIFDescriptorReader {
+	return &LDIFDescriptorReader{lr: newLDIFReader(rdr)}
+}
+
+// Next returns the next entry carrying a security descriptor, or io.EOF
+// once the stream is exhausted
+func (r *LDIFDescriptorReader) Next() (DirectoryObject, error) {
+	for {
+		record, err := r.lr.next()
+		if err != nil {
+			return DirectoryObject{}, err
+		}
+
+		rawNTSD := record.Value("nTSecurityDescriptor")
+		if rawNTSD == nil {
+			continue
+		}
+
+		object := DirectoryObject{DN: record.DN}
+		for _, class := range record.Values("objectClass") {
+			object.ObjectClass = append(object.ObjectClass, string(class))
+		}
+
+		object.SecurityDescriptor, err = NewNtSecurityDescriptor(rawNTSD)
+		if err != nil {
+			return object, fmt.Errorf("ldif: %s: %w", record.DN, err)
+		}
+		return object, nil
+	}
+}
+
+// ReadLDIFDescriptors returns every entry of an LDIF export that
+// carries a security descriptor
+func ReadLDIFDescriptors(rdr io.Reader) ([]DirectoryObject, error) {
+	objects := []DirectoryObject{}
+	lr := NewLDIFDescriptorReader(rdr)
+
+	for {
+		object, err := lr.Next()
+		if err == io.EOF {
+			return objects, nil
+		}
+		if err != nil {
+			return objects, err
+		}
+		objects = append(objects, object)
+	}
+}
+
+// ldifRecord holds a single LDIF entry. Attribute names are stored
+// lowercased and stripped of any options (";binary", ";range=0-1499")
+type ldifRecord struct {
+	DN         string
+	Attributes map[string][][]byte
+}
+
+// Values returns every value of the named attribute
+func (r ldifRecord) Values(name string) [][]byte {
+	return r.Attributes[strings.ToLower(name)]
+}
+
+// Value returns the first value of the named attribute, or nil
+// if the attribute is not present
+func (r ldifRecord) Value(name string) []byte {
+	values := r.Values(name)
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
+
+// StringValue returns the first value of the named attribute as a string
+func (r ldifRecord) StringValue(name string) string {
+	return string(r.Value(name))
+}
+
+// HasValue reports whether the named attribute contains the given value,
+// compared case-insensitively
+func (r ldifRecord) HasValue(name string, value string) bool {
+	for _, v := range r.Values(name) {
+		if strings.EqualFold(string(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ldifReader reads content records, such as those produced by ldapsearch,
+// from an LDIF stream. Folded lines, comments and base64 encoded values
+// are handled; records without a DN (version lines, search summaries)
+// are skipped.
+type ldifReader struct {
+	rdr    *bufio.Reader
+	lineNo int
+	eof    bool
+}
+
+func newLDIFReader(rdr io.Reader) *ldifReader {
+	return &ldifReader{rdr: bufio.NewReader(rdr)}
+}
+
+// next returns the next record in the stream, or io.EOF once the
+// stream is exhausted
+func (lr *ldifReader) next() (ldifRecord, error) {
+	for {
+		lines, err := lr.readRecordLines()
+		if err != nil {
+			return ldifRecord{}, err
+		}
+
+		record, err := parseLDIFRecord(lines)
+		if err != nil {
+			return record, err
+		}
+		if record.DN != "" {
+			return record, nil
+		}
+	}
+}
+
+// readRecordLines returns the unfolded lines making up the next
+// record, skipping comments
+func (lr *ldifReader) readRecordLines() ([]string, error) {
+	var lines []string
+	inComment := false
+
+	for {
+		if lr.eof {
+			if len(lines) == 0 {
+				return nil, io.EOF
+			}
+			return lines, nil
+		}
+
+		line, err := lr.rdr.ReadString('\n')
+		if err == io.EOF {
+			lr.eof = true
+			if line == "" {
+				continue
+			}
+		} else if err != nil {
+			return nil, err
+		}
+		lr.lineNo++
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			inComment = false
+			if len(lines) > 0 {
+				return lines, nil
+			}
+		case strings.HasPrefix(line, " "):
+			if inComment {
+				continue
+			}
+			if len(lines) == 0 {
+				return nil, fmt.Errorf("ldif: line %d: continuation without a preceding line", lr.lineNo)
+			}
+			lines[len(lines)-1] += line[1:]
+		case strings.HasPrefix(line, "#"):
+			inComment = true
+		default:
+			inComment = false
+			lines = append(lines, line)
+		}
+	}
+}
+
+func parseLDIFRecord(lines []string) (ldifRecord, error) {
+	record := ldifRecord{Attributes: make(map[string][][]byte)}
+
+	for _, line := range lines {
+		if line == "-" {
+			continue
+		}
+
+		sep := strings.Index(line, ":")
+		if sep < 1 {
+			return record, fmt.Errorf("ldif: malformed line %q", line)
+		}
+
+		name := strings.ToLower(line[:sep])
+		if opt := strings.Index(name, ";"); opt >= 0 {
+			name = name[:opt]
+		}
+
+		value, err := decodeLDIFValue(line[sep+1:])
+		if err != nil {
+			return record, fmt.Errorf("ldif: attribute %s: %w", name, err)
+		}
+
+		if name == "dn" {
+			record.DN = string(value)
+			continue
+		}
+		record.Attributes[name] = append(record.Attributes[name], value)
+	}
+
+	return record, nil
+}
+
+// decodeLDIFValue decodes everything following the first colon of an
+// attribute line
+func decodeLDIFValue(raw string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(raw, ":"):
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(raw[1:]))
+	case strings.HasPrefix(raw, "<"):
+		return nil, fmt.Errorf("URL values are not supported")
+	default:
+		return []byte(strings.TrimLeft(raw, " ")), nil
+	}
+}
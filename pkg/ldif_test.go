@@ -0,0 +1,51 @@
+package winacl_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLDIFDescriptorReader(t *testing.T) {
+
+	r := require.New(t)
+
+	t.Run("Reads descriptors from an ldapsearch export", func(t *testing.T) {
+		file, err := os.Open(filepath.Join(getTestDataDir(), "objects.ldif"))
+		r.NoError(err)
+		defer file.Close()
+
+		objects, err := winacl.ReadLDIFDescriptors(file)
+		r.NoError(err)
+		r.Len(objects, 2)
+
+		r.Equal("CN=jdoe,CN=Users,DC=corp,DC=local", objects[0].DN)
+		r.Equal("user", objects[0].Class())
+		r.Equal(newTestSD(), objects[0].SecurityDescriptor)
+
+		r.Equal("CN=WS01,OU=Workstations,DC=corp,DC=local", objects[1].DN)
+		r.Equal([]string{"top", "person", "organizationalPerson", "user", "computer"}, objects[1].ObjectClass)
+		r.Equal(newTestSD().DACL, objects[1].SecurityDescriptor.DACL)
+		// WS01 was read with its empty, auto-inherited SACL
+		r.Equal(newTestSD().ToSDDL()+"S:AI", objects[1].SecurityDescriptor.ToSDDL())
+	})
+
+	t.Run("Returns io.EOF once exhausted", func(t *testing.T) {
+		lr := winacl.NewLDIFDescriptorReader(strings.NewReader("version: 1\n\ndn: CN=x\ncn: x\n"))
+		_, err := lr.Next()
+		r.Equal(io.EOF, err)
+	})
+
+	t.Run("Reports the DN of malformed descriptors", func(t *testing.T) {
+		lr := winacl.NewLDIFDescriptorReader(strings.NewReader("dn: CN=x\nnTSecurityDescriptor:: AQAEjA==\n"))
+		_, err := lr.Next()
+		r.Error(err)
+		r.Contains(err.Error(), "CN=x")
+	})
+
+}
@@ -0,0 +1,419 @@
+package winacl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ntsdRevision is the only security descriptor revision defined
+const ntsdRevision = 1
+
+// Diagnostic is a problem reported by a LintRule. ACL names the ACL the
+// problem was found in, if any, and AceIndex the offending ACE's
+// position in it, or -1 when the problem concerns the ACL or the
+// descriptor as a whole
+type Diagnostic struct {
+	RuleID   string   `json:"ruleId"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	ACL      string   `json:"acl,omitempty"`
+	AceIndex int      `json:"aceIndex"`
+}
+
+// String returns an human-readable representation of a Diagnostic
+func (d Diagnostic) String() string {
+	location := "descriptor"
+	if d.ACL != "" {
+		location = d.ACL
+		if d.AceIndex >= 0 {
+			location = fmt.Sprintf("%s ACE %d", d.ACL, d.AceIndex)
+		}
+	}
+	return fmt.Sprintf("[%s] %s: %s: %s", d.Severity, d.RuleID, location, d.Message)
+}
+
+// LintRule is a check run by a Linter. Check returns the problems found,
+// leaving their RuleID and Severity for the Linter to fill in. Structural
+// rules check the binary layout, the others check security policy
+type LintRule struct {
+	ID         string
+	Severity   Severity
+	Structural bool
+	Check      func(ntsd NtSecurityDescriptor) []Diagnostic
+}
+
+// Linter runs a configurable set of LintRules over descriptors
+type Linter struct {
+	rules      []LintRule
+	disabled   map[string]bool
+	severities map[string]Severity
+}
+
+// NewLinter is a constructor for a Linter running the given rules, e.g.
+// NewLinter(DefaultLintRules()...)
+func NewLinter(rules ...LintRule) *Linter {
+	return &Linter{
+		rules:      rules,
+		disabled:   make(map[string]bool),
+		severities: make(map[string]Severity),
+	}
+}
+
+// AddRules registers additional rules with the linter
+func (l *Linter) AddRules(rules ...LintRule) {
+	l.rules = append(l.rules, rules...)
+}
+
+// Rules returns the rules registered with the linter
+func (l *Linter) Rules() []LintRule {
+	return l.rules
+}
+
+// Disable turns off the rules with the given IDs
+func (l *Linter) Disable(ids ...string) {
+	for _, id := range ids {
+		l.disabled[id] = true
+	}
+}
+
+// Enable turns back on rules turned off with Disable
+func (l *Linter) Enable(ids ...string) {
+	for _, id := range ids {
+		delete(l.disabled, id)
+	}
+}
+
+// SetSeverity overrides the severity the rule with the given ID reports
+// its diagnostics with
+func (l *Linter) SetSeverity(id string, severity Severity) {
+	l.severities[id] = severity
+}
+
+// Lint runs every enabled rule against a descriptor and returns the
+// diagnostics, most severe first
+func (l *Linter) Lint(ntsd NtSecurityDescriptor) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, rule := range l.rules {
+		if l.disabled[rule.ID] {
+			continue
+		}
+
+		severity, overridden := l.severities[rule.ID]
+		if !overridden {
+			severity = rule.Severity
+		}
+		for _, diagnostic := range rule.Check(ntsd) {
+			diagnostic.RuleID = rule.ID
+			diagnostic.Severity = severity
+			diagnostics = append(diagnostics, diagnostic)
+		}
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Severity > diagnostics[j].Severity
+	})
+	return diagnostics
+}
+
+// Lint runs the DefaultLintRules against the descriptor
+func (s NtSecurityDescriptor) Lint() []Diagnostic {
+	return NewLinter(DefaultLintRules()...).Lint(s)
+}
+
+// Validate runs the structural DefaultLintRules against the descriptor
+// and returns an error listing the problems found, if any
+func (s NtSecurityDescriptor) Validate() error {
+	structural := []LintRule{}
+	for _, rule := range DefaultLintRules() {
+		if rule.Structural {
+			structural = append(structural, rule)
+		}
+	}
+
+	diagnostics := NewLinter(structural...).Lint(s)
+	if len(diagnostics) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		messages = append(messages, diagnostic.String())
+	}
+	return NtSecurityDescriptorInvalidError{strings.Join(messages, "; ")}
+}
+
+// DefaultLintRules returns the built-in lint rules
+func DefaultLintRules() []LintRule {
+	return []LintRule{
+		{
+			ID:         "sd-revision",
+			Severity:   SeverityHigh,
+			Structural: true,
+			Check: func(ntsd NtSecurityDescriptor) []Diagnostic {
+				if ntsd.Header.Revision == ntsdRevision {
+					return nil
+				}
+				return []Diagnostic{descriptorDiagnostic("revision is %d, not %d", ntsd.Header.Revision, ntsdRevision)}
+			},
+		},
+		{
+			ID:         "sd-sbz",
+			Severity:   SeverityLow,
+			Structural: true,
+			Check: func(ntsd NtSecurityDescriptor) []Diagnostic {
+				if ntsd.Header.Sbz1 == 0 || ntsd.Header.Control&RMControlValid != 0 {
+					return nil
+				}
+				return []Diagnostic{descriptorDiagnostic("Sbz1 is 0x%02x without SE_RM_CONTROL_VALID", ntsd.Header.Sbz1)}
+			},
+		},
+		{
+			ID:         "acl-revision",
+			Severity:   SeverityHigh,
+			Structural: true,
+			Check:      forEachACL(checkACLRevision),
+		},
+		{
+			ID:         "acl-sbz",
+			Severity:   SeverityLow,
+			Structural: true,
+			Check: forEachACL(func(name string, acl ACL) []Diagnostic {
+				if acl.Header.Sbz1 == 0 && acl.Header.Sbz2 == 0 {
+					return nil
+				}
+				return []Diagnostic{aclDiagnostic(name, "Sbz1 is 0x%02x and Sbz2 is 0x%04x", acl.Header.Sbz1, acl.Header.Sbz2)}
+			}),
+		},
+		{
+			ID:         "acl-ace-count",
+			Severity:   SeverityHigh,
+			Structural: true,
+			Check: forEachACL(func(name string, acl ACL) []Diagnostic {
+				if int(acl.Header.AceCount) == len(acl.Aces) {
+					return nil
+				}
+				return []Diagnostic{aclDiagnostic(name, "AceCount is %d but the ACL holds %d ACEs", acl.Header.AceCount, len(acl.Aces))}
+			}),
+		},
+		{
+			ID:         "acl-size",
+			Severity:   SeverityHigh,
+			Structural: true,
+			Check:      forEachACL(checkACLSize),
+		},
+		{
+			ID:         "ace-size",
+			Severity:   SeverityHigh,
+			Structural: true,
+			Check:      forEachACE(checkACESize),
+		},
+		{
+			ID:         "ace-object-flags",
+			Severity:   SeverityMedium,
+			Structural: true,
+			Check:      forEachACE(checkACEObjectFlags),
+		},
+		{
+			ID:         "acl-canonical-order",
+			Severity:   SeverityMedium,
+			Structural: true,
+			Check:      checkCanonicalOrder,
+		},
+		{
+			ID:       "dacl-null",
+			Severity: SeverityCritical,
+			Check: func(ntsd NtSecurityDescriptor) []Diagnostic {
+				if ntsd.DACL != nil {
+					return nil
+				}
+				return []Diagnostic{descriptorDiagnostic("the descriptor has a NULL DACL, which grants everyone full control")}
+			},
+		},
+		{
+			ID:       "dacl-empty",
+			Severity: SeverityHigh,
+			Check: func(ntsd NtSecurityDescriptor) []Diagnostic {
+				if ntsd.DACL == nil || len(ntsd.DACL.Aces) > 0 {
+					return nil
+				}
+				return []Diagnostic{aclDiagnostic(ACLNameDACL, "the DACL is empty and denies everyone but the owner")}
+			},
+		},
+		{
+			ID:       "owner-missing",
+			Severity: SeverityHigh,
+			Check: func(ntsd NtSecurityDescriptor) []Diagnostic {
+				if ntsd.Owner.String() != "" {
+					return nil
+				}
+				return []Diagnostic{descriptorDiagnostic("the descriptor has no owner")}
+			},
+		},
+		{
+			ID:       "protected-inherited-aces",
+			Severity: SeverityMedium,
+			Check:    checkProtectedInheritedAces,
+		},
+	}
+}
+
+// forEachACL runs check against the DACL and SACL, when present
+func forEachACL(check func(name string, acl ACL) []Diagnostic) func(NtSecurityDescriptor) []Diagnostic {
+	return func(ntsd NtSecurityDescriptor) []Diagnostic {
+		diagnostics := []Diagnostic{}
+		if ntsd.DACL != nil {
+			diagnostics = append(diagnostics, check(ACLNameDACL, *ntsd.DACL)...)
+		}
+		if ntsd.SACL.isPresent() {
+			diagnostics = append(diagnostics, check(ACLNameSACL, ntsd.SACL)...)
+		}
+		return diagnostics
+	}
+}
+
+// forEachACE runs check against every ACE of the DACL and SACL
+func forEachACE(check func(ace ACE) string) func(NtSecurityDescriptor) []Diagnostic {
+	return forEachACL(func(name string, acl ACL) []Diagnostic {
+		diagnostics := []Diagnostic{}
+		for idx, ace := range acl.Aces {
+			if message := check(ace); message != "" {
+				diagnostics = append(diagnostics, Diagnostic{Message: message, ACL: name, AceIndex: idx})
+			}
+		}
+		return diagnostics
+	})
+}
+
+func checkACLRevision(name string, acl ACL) []Diagnostic {
+	switch acl.Header.Revision {
+	case ACLRevision:
+		for idx, ace := range acl.Aces {
+			if _, isObject := ace.ObjectAce.(AdvancedAce); isObject {
+				return []Diagnostic{{
+					Message:  fmt.Sprintf("object ACE in an ACL of revision %d instead of %d", ACLRevision, ACLRevisionDS),
+					ACL:      name,
+					AceIndex: idx,
+				}}
+			}
+		}
+		return nil
+	case ACLRevisionDS:
+		return nil
+	default:
+		return []Diagnostic{aclDiagnostic(name, "revision is %d, not %d or %d", acl.Header.Revision, ACLRevision, ACLRevisionDS)}
+	}
+}
+
+func checkACLSize(name string, acl ACL) []Diagnostic {
+	size := acl.Size()
+	switch {
+	case int(acl.Header.Size) < size:
+		return []Diagnostic{aclDiagnostic(name, "Size is %d but the ACEs take %d bytes", acl.Header.Size, size)}
+	case acl.Header.Size%4 != 0:
+		return []Diagnostic{aclDiagnostic(name, "Size %d is not a multiple of 4", acl.Header.Size)}
+	}
+	return nil
+}
+
+func checkACESize(ace ACE) string {
+	if ace.ObjectAce == nil {
+		return ""
+	}
+
+	// Callback ACEs carry application data after the SID
+	size := ace.Size()
+	switch {
+	case int(ace.Header.Size) < size:
+		return fmt.Sprintf("Size is %d but the ACE takes %d bytes", ace.Header.Size, size)
+	case int(ace.Header.Size) > size && !isCallbackACE(ace):
+		return fmt.Sprintf("Size is %d but the ACE takes %d bytes", ace.Header.Size, size)
+	case ace.Header.Size%4 != 0:
+		return fmt.Sprintf("Size %d is not a multiple of 4", ace.Header.Size)
+	}
+	return ""
+}
+
+func checkACEObjectFlags(ace ACE) string {
+	aa, isObject := ace.ObjectAce.(AdvancedAce)
+	if !isObject {
+		return ""
+	}
+
+	known := ACEInheritanceFlags(ACEInheritanceFlagsObjectTypePresent | ACEInheritanceFlagsInheritedObjectTypePresent)
+	switch {
+	case aa.Flags&^known != 0:
+		return fmt.Sprintf("undefined object flags 0x%x", uint32(aa.Flags&^known))
+	case aa.Flags&ACEInheritanceFlagsObjectTypePresent != 0 && aa.ObjectType.IsNull():
+		return "ACE_OBJECT_TYPE_PRESENT is set but ObjectType is the null GUID"
+	case aa.Flags&ACEInheritanceFlagsObjectTypePresent == 0 && !aa.ObjectType.IsNull():
+		return "ObjectType is set without ACE_OBJECT_TYPE_PRESENT"
+	case aa.Flags&ACEInheritanceFlagsInheritedObjectTypePresent != 0 && aa.InheritedObjectType.IsNull():
+		return "ACE_INHERITED_OBJECT_TYPE_PRESENT is set but InheritedObjectType is the null GUID"
+	case aa.Flags&ACEInheritanceFlagsInheritedObjectTypePresent == 0 && !aa.InheritedObjectType.IsNull():
+		return "InheritedObjectType is set without ACE_INHERITED_OBJECT_TYPE_PRESENT"
+	}
+	return ""
+}
+
+// checkCanonicalOrder reports DACL entries placed after ACEs that sort
+// later in canonical order: explicit deny, explicit allow, inherited
+func checkCanonicalOrder(ntsd NtSecurityDescriptor) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	highest := 0
+	for idx, ace := range ntsd.daclAces() {
+		rank := canonicalRank(ace)
+		if rank < highest {
+			diagnostics = append(diagnostics, Diagnostic{
+				Message:  "ACE is out of canonical order (explicit deny, explicit allow, inherited)",
+				ACL:      ACLNameDACL,
+				AceIndex: idx,
+			})
+		}
+		if rank > highest {
+			highest = rank
+		}
+	}
+	return diagnostics
+}
+
+func checkProtectedInheritedAces(ntsd NtSecurityDescriptor) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	check := func(name string, aces []ACE, protected bool) {
+		if !protected {
+			return
+		}
+		for idx, ace := range aces {
+			if ace.Header.Flags&ACEHeaderFlagsInheritedAce != 0 {
+				diagnostics = append(diagnostics, Diagnostic{
+					Message:  fmt.Sprintf("inherited ACE in a protected %s", name),
+					ACL:      name,
+					AceIndex: idx,
+				})
+			}
+		}
+	}
+
+	check(ACLNameDACL, ntsd.daclAces(), ntsd.Header.Control&DACLProtected != 0)
+	check(ACLNameSACL, ntsd.SACL.Aces, ntsd.Header.Control&SACLProtected != 0)
+	return diagnostics
+}
+
+// isCallbackACE reports whether an ACE carries a callback condition
+func isCallbackACE(ace ACE) bool {
+	switch ace.Header.Type {
+	case AceTypeAccessAllowedCallback, AceTypeAccessDeniedCallback,
+		AceTypeSystemAuditCallback, AceTypeSystemAlarmCallback,
+		AceTypeAccessAllowedCallbackObject, AceTypeAccessDeniedCallbackObject,
+		AceTypeSystemAuditCallbackObject, AceTypeSystemAlarmCallbackObject:
+		return true
+	}
+	return false
+}
+
+func descriptorDiagnostic(format string, args ...interface{}) Diagnostic {
+	return Diagnostic{Message: fmt.Sprintf(format, args...), AceIndex: -1}
+}
+
+func aclDiagnostic(name string, format string, args ...interface{}) Diagnostic {
+	return Diagnostic{Message: fmt.Sprintf(format, args...), ACL: name, AceIndex: -1}
+}
@@ -0,0 +1,108 @@
+package winacl_test
+
+import (
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func lintRuleIDs(diagnostics []winacl.Diagnostic) []string {
+	ids := []string{}
+	for _, diagnostic := range diagnostics {
+		ids = append(ids, diagnostic.RuleID)
+	}
+	return ids
+}
+
+func TestLint(t *testing.T) {
+
+	r := require.New(t)
+
+	everyone, _ := winacl.ParseSID("S-1-1-0")
+	system, _ := winacl.ParseSID("S-1-5-18")
+
+	t.Run("Accepts parsed and built descriptors", func(t *testing.T) {
+		r.NoError(newTestSD().Validate())
+		r.Empty(newTestSD().Lint())
+
+		ntsd, err := winacl.NewDescriptor().Owner(system).Allow(everyone, winacl.AccessMaskReadControl).Build()
+		r.NoError(err)
+		r.NoError(ntsd.Validate())
+	})
+
+	t.Run("Reports structural problems", func(t *testing.T) {
+		ntsd := newTestSD()
+		ntsd.Header.Revision = 2
+		ntsd.DACL.Header.AceCount++
+		ntsd.DACL.Header.Sbz2 = 1
+		ntsd.DACL.Aces[0].Header.Size += 4
+
+		diagnostics := ntsd.Lint()
+		r.ElementsMatch([]string{"sd-revision", "acl-ace-count", "ace-size", "acl-sbz"}, lintRuleIDs(diagnostics))
+		r.Equal(winacl.SeverityHigh, diagnostics[0].Severity)
+		r.Equal(winacl.SeverityLow, diagnostics[3].Severity)
+
+		r.Equal(winacl.ACLNameDACL, diagnostics[2].ACL)
+		r.Equal(0, diagnostics[2].AceIndex)
+		r.Equal("[HIGH] ace-size: DACL ACE 0: Size is 60 but the ACE takes 56 bytes", diagnostics[2].String())
+
+		err := ntsd.Validate()
+		r.Error(err)
+		r.IsType(winacl.NtSecurityDescriptorInvalidError{}, err)
+	})
+
+	t.Run("Reports object flags inconsistent with GUIDs", func(t *testing.T) {
+		ntsd := newTestSD()
+		for idx, ace := range ntsd.DACL.Aces {
+			if aa, ok := ace.ObjectAce.(winacl.AdvancedAce); ok {
+				aa.Flags = 0
+				ntsd.DACL.Aces[idx].ObjectAce = aa
+				break
+			}
+		}
+		r.Contains(lintRuleIDs(ntsd.Lint()), "ace-object-flags")
+	})
+
+	t.Run("Reports policy problems", func(t *testing.T) {
+		ntsd, err := winacl.NewDescriptor().
+			Protected().
+			Allow(everyone, winacl.AccessMaskReadControl).
+			Allow(system, winacl.AccessMaskGenericAll, winacl.ACEHeaderFlagsInheritedAce).
+			Deny(everyone, winacl.AccessMaskDelete).
+			Build()
+		r.NoError(err)
+
+		// Builders keep ACEs in the order they were added
+		diagnostics := ntsd.Lint()
+		r.Equal([]string{"owner-missing", "acl-canonical-order", "protected-inherited-aces"}, lintRuleIDs(diagnostics))
+		r.Equal(2, diagnostics[1].AceIndex)
+		r.Equal(1, diagnostics[2].AceIndex)
+
+		empty, err := winacl.NewDescriptor().Owner(system).EmptyDACL().Build()
+		r.NoError(err)
+		r.Equal([]string{"dacl-empty"}, lintRuleIDs(empty.Lint()))
+
+		null, err := winacl.NewDescriptor().Owner(system).Build()
+		r.NoError(err)
+		r.Equal([]string{"dacl-null"}, lintRuleIDs(null.Lint()))
+		r.NoError(null.Validate())
+	})
+
+	t.Run("Disables rules and overrides severities", func(t *testing.T) {
+		null, err := winacl.NewDescriptor().Build()
+		r.NoError(err)
+
+		linter := winacl.NewLinter(winacl.DefaultLintRules()...)
+		linter.Disable("owner-missing")
+		linter.SetSeverity("dacl-null", winacl.SeverityInfo)
+
+		diagnostics := linter.Lint(null)
+		r.Len(diagnostics, 1)
+		r.Equal("dacl-null", diagnostics[0].RuleID)
+		r.Equal(winacl.SeverityInfo, diagnostics[0].Severity)
+
+		linter.Enable("owner-missing")
+		r.Len(linter.Lint(null), 2)
+	})
+}
@@ -0,0 +1,271 @@
+package winacl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ImplicitGroupSIDs are the well-known groups every authenticated
+// principal's token holds regardless of directory membership:
+// Everyone and Authenticated Users
+var ImplicitGroupSIDs = []string{"S-1-1-0", "S-1-5-11"}
+
+// Membership is a graph of group memberships used to compute the
+// groups held in a principal's access token
+type Membership struct {
+	// memberOf maps a member's SID to the SIDs of its direct groups
+	memberOf map[string]map[string]bool
+	// dnSIDs maps lowercased DNs to the SIDs of the objects loaded
+	dnSIDs map[string]string
+	// pending holds LDIF member links whose DNs are not resolved yet
+	pending []membershipLink
+}
+
+type membershipLink struct {
+	groupDN  string
+	memberDN string
+}
+
+type membershipJSON struct {
+	MemberOf map[string][]string `json:"memberOf"`
+}
+
+// NewMembership is a constructor for an empty Membership graph
+func NewMembership() *Membership {
+	return &Membership{
+		memberOf: make(map[string]map[string]bool),
+		dnSIDs:   make(map[string]string),
+	}
+}
+
+// AddMember records that member is a direct member of group
+func (m *Membership) AddMember(group SID, member SID) {
+	m.addMember(group.String(), member.String())
+}
+
+func (m *Membership) addMember(group string, member string) {
+	if group == member {
+		return
+	}
+	if m.memberOf[member] == nil {
+		m.memberOf[member] = make(map[string]bool)
+	}
+	m.memberOf[member][group] = true
+}
+
+// DirectGroups returns the groups sid is a direct member of, sorted
+func (m *Membership) DirectGroups(sid SID) []SID {
+	return sortedSIDs(m.memberOf[sid.String()])
+}
+
+// TokenGroups returns every group sid transitively belongs to, followed
+// by the ImplicitGroupSIDs. The principal itself is not included and
+// membership cycles are tolerated
+func (m *Membership) TokenGroups(sid SID) []SID {
+	principal := sid.String()
+	seen := map[string]bool{principal: true}
+	queue := []string{principal}
+	groups := []SID{}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, group := range sortedSIDs(m.memberOf[current]) {
+			groupStr := group.String()
+			if seen[groupStr] {
+				continue
+			}
+			seen[groupStr] = true
+			groups = append(groups, group)
+			queue = append(queue, groupStr)
+		}
+	}
+
+	for _, implicit := range ImplicitGroupSIDs {
+		if seen[implicit] {
+			continue
+		}
+		seen[implicit] = true
+		if group, err := ParseSID(implicit); err == nil {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// LoadLDIF adds the memberships found in an LDIF export of users,
+// computers and groups, such as:
+//
+//	ldapsearch -b DC=corp,DC=local '(|(objectClass=user)(objectClass=group))' \
+//		objectSid member memberOf primaryGroupID
+//
+// Both member and memberOf links are followed, primaryGroupID is resolved
+// against the domain of the object's SID, and members stored under
+// CN=ForeignSecurityPrincipals are identified by the SID in their CN.
+// Links to DNs that are not part of the export are kept until a later
+// load resolves them
+func (m *Membership) LoadLDIF(rdr io.Reader) error {
+	lr := newLDIFReader(rdr)
+
+	for {
+		record, err := lr.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := m.loadLDIFRecord(record); err != nil {
+			return fmt.Errorf("Membership.LoadLDIF: %s: %w", record.DN, err)
+		}
+	}
+
+	m.resolvePending()
+	return nil
+}
+
+func (m *Membership) loadLDIFRecord(record ldifRecord) error {
+	dn := normalizeDN(record.DN)
+
+	if rawSID := record.Value("objectSid"); rawSID != nil {
+		if len(rawSID) < 8 {
+			return SIDInvalidError{"invalid SID length"}
+		}
+		sid, err := NewSID(bytes.NewBuffer(rawSID), len(rawSID))
+		if err != nil {
+			return err
+		}
+		m.dnSIDs[dn] = sid.String()
+
+		if rawRID := record.StringValue("primaryGroupID"); rawRID != "" {
+			rid, err := strconv.ParseUint(rawRID, 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid primaryGroupID %q", rawRID)
+			}
+			if domain := domainSIDOf(sid); domain != "" {
+				m.addMember(fmt.Sprintf("%s-%d", domain, rid), sid.String())
+			}
+		}
+	}
+
+	for _, member := range record.Values("member") {
+		m.pending = append(m.pending, membershipLink{groupDN: dn, memberDN: normalizeDN(string(member))})
+	}
+	for _, group := range record.Values("memberOf") {
+		m.pending = append(m.pending, membershipLink{groupDN: normalizeDN(string(group)), memberDN: dn})
+	}
+	return nil
+}
+
+// resolvePending turns member links into SID memberships, keeping the
+// links whose DNs are still unknown
+func (m *Membership) resolvePending() {
+	unresolved := []membershipLink{}
+	for _, link := range m.pending {
+		group, groupFound := m.sidOfDN(link.groupDN)
+		member, memberFound := m.sidOfDN(link.memberDN)
+		if !groupFound || !memberFound {
+			unresolved = append(unresolved, link)
+			continue
+		}
+		m.addMember(group, member)
+	}
+	m.pending = unresolved
+}
+
+func (m *Membership) sidOfDN(dn string) (string, bool) {
+	if sid, found := m.dnSIDs[dn]; found {
+		return sid, true
+	}
+
+	// CN=S-1-5-21-...,CN=ForeignSecurityPrincipals,DC=...
+	if parts := strings.SplitN(dn, ",", 2); len(parts) == 2 &&
+		strings.HasPrefix(parts[1], "cn=foreignsecurityprincipals,") {
+		if sid, err := ParseSID(strings.TrimPrefix(parts[0], "cn=")); err == nil {
+			return sid.String(), true
+		}
+	}
+	return "", false
+}
+
+// MarshalJSON encodes the graph as a map of member SIDs to the SIDs of
+// their direct groups
+func (m *Membership) MarshalJSON() ([]byte, error) {
+	encoded := membershipJSON{MemberOf: make(map[string][]string)}
+	for member, groups := range m.memberOf {
+		for _, group := range sortedSIDs(groups) {
+			encoded.MemberOf[member] = append(encoded.MemberOf[member], group.String())
+		}
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON adds the memberships of a graph encoded by MarshalJSON
+func (m *Membership) UnmarshalJSON(data []byte) error {
+	var decoded membershipJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	if m.memberOf == nil {
+		*m = *NewMembership()
+	}
+	for member, groups := range decoded.MemberOf {
+		memberSID, err := ParseSID(member)
+		if err != nil {
+			return err
+		}
+		for _, group := range groups {
+			groupSID, err := ParseSID(group)
+			if err != nil {
+				return err
+			}
+			m.AddMember(groupSID, memberSID)
+		}
+	}
+	return nil
+}
+
+// LoadJSON adds the memberships of a graph encoded by MarshalJSON
+func (m *Membership) LoadJSON(rdr io.Reader) error {
+	return json.NewDecoder(rdr).Decode(m)
+}
+
+// domainSIDOf returns the domain part of a domain account's SID
+func domainSIDOf(sid SID) string {
+	if domainRID(sid) == 0 {
+		return ""
+	}
+	sidStr := sid.String()
+	return sidStr[:strings.LastIndex(sidStr, "-")]
+}
+
+func normalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for idx, part := range parts {
+		parts[idx] = strings.TrimSpace(part)
+	}
+	return strings.ToLower(strings.Join(parts, ","))
+}
+
+func sortedSIDs(set map[string]bool) []SID {
+	sidStrs := make([]string, 0, len(set))
+	for sidStr := range set {
+		sidStrs = append(sidStrs, sidStr)
+	}
+	sort.Strings(sidStrs)
+
+	sids := make([]SID, 0, len(sidStrs))
+	for _, sidStr := range sidStrs {
+		if sid, err := ParseSID(sidStr); err == nil {
+			sids = append(sids, sid)
+		}
+	}
+	return sids
+}
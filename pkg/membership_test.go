@@ -0,0 +1,82 @@
+package winacl_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+const testDomainSID = "S-1-5-21-1004336348-1177238915-682003330"
+
+func newTestMembership() (*winacl.Membership, error) {
+	file, err := os.Open(filepath.Join(getTestDataDir(), "membership.ldif"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	membership := winacl.NewMembership()
+	return membership, membership.LoadLDIF(file)
+}
+
+func sidStrings(sids []winacl.SID) []string {
+	strs := []string{}
+	for _, sid := range sids {
+		strs = append(strs, sid.String())
+	}
+	return strs
+}
+
+func TestMembership(t *testing.T) {
+
+	r := require.New(t)
+
+	membership, err := newTestMembership()
+	r.NoError(err)
+
+	alice, _ := winacl.ParseSID(testDomainSID + "-1105")
+	foreign, _ := winacl.ParseSID("S-1-5-21-9-9-9-1200")
+	workstation, _ := winacl.ParseSID(testDomainSID + "-1120")
+
+	t.Run("Expands nested groups from LDIF", func(t *testing.T) {
+		r.Equal([]string{testDomainSID + "-1110", testDomainSID + "-513"}, sidStrings(membership.DirectGroups(alice)))
+		r.Equal([]string{
+			testDomainSID + "-1110",
+			testDomainSID + "-513",
+			testDomainSID + "-1111",
+			"S-1-5-32-555",
+			"S-1-1-0",
+			"S-1-5-11",
+		}, sidStrings(membership.TokenGroups(alice)))
+	})
+
+	t.Run("Resolves primary groups and foreign principals", func(t *testing.T) {
+		r.Equal([]string{testDomainSID + "-515", "S-1-1-0", "S-1-5-11"}, sidStrings(membership.TokenGroups(workstation)))
+		r.Contains(sidStrings(membership.TokenGroups(foreign)), "S-1-5-32-555")
+	})
+
+	t.Run("Resolves links across loads", func(t *testing.T) {
+		m := winacl.NewMembership()
+		r.NoError(m.LoadLDIF(strings.NewReader("dn: CN=Bob,DC=corp\nobjectSid:: AQEAAAAAAAUSAAAA\nmemberOf: CN=Ops,DC=corp\n")))
+		bob, _ := winacl.ParseSID("S-1-5-18")
+		r.Empty(m.DirectGroups(bob))
+
+		r.NoError(m.LoadLDIF(strings.NewReader("dn: cn=Ops, dc=corp\nobjectSid:: AQIAAAAAAAUgAAAAIgIAAA==\n")))
+		r.Equal([]string{"S-1-5-32-546"}, sidStrings(m.DirectGroups(bob)))
+	})
+
+	t.Run("Round-trips through JSON", func(t *testing.T) {
+		encoded, err := json.Marshal(membership)
+		r.NoError(err)
+
+		decoded := winacl.NewMembership()
+		r.NoError(decoded.LoadJSON(strings.NewReader(string(encoded))))
+		r.Equal(membership.TokenGroups(alice), decoded.TokenGroups(alice))
+	})
+
+}
@@ -2,13 +2,16 @@ package winacl
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 )
 
-// NtSecurityDescriptor represent a Security Descriptor
+// NtSecurityDescriptor represent a Security Descriptor. A nil DACL is a
+// NULL DACL, which grants everyone full access, as opposed to an empty
+// DACL, which grants nobody any access
 type NtSecurityDescriptor struct {
 	Header NtSecurityDescriptorHeader
-	DACL   ACL
+	DACL   *ACL
 	SACL   ACL
 	Owner  SID
 	Group  SID
@@ -27,7 +30,10 @@ func (s NtSecurityDescriptor) String() string {
 }
 
 // NewNtSecurityDescriptor is a constructor that will parse out an
-// NtSecurityDescriptor from a byte buffer
+// NtSecurityDescriptor from a byte buffer. The owner, group, SACL and
+// DACL are read from the offsets in the header; a zero offset leaves the
+// corresponding field empty, and the DACL nil. The descriptor must be
+// self-relative
 func NewNtSecurityDescriptor(ntsdBytes []byte) (NtSecurityDescriptor, error) {
 	var buf = bytes.NewBuffer(ntsdBytes)
 	var err error
@@ -37,17 +43,136 @@ func NewNtSecurityDescriptor(ntsdBytes []byte) (NtSecurityDescriptor, error) {
 	if err != nil {
 		return ntsd, err
 	}
+	if !ntsd.Header.IsSelfRelative() {
+		return ntsd, NtSecurityDescriptorInvalidError{"SelfRelative is not set; absolute descriptors are parsed by NewAbsoluteSecurityDescriptor"}
+	}
+
+	if ntsd.Header.OffsetSacl != 0 {
+		ntsd.SACL, err = parseACLAt(ntsdBytes, ntsd.Header.OffsetSacl)
+		if err != nil {
+			return ntsd, err
+		}
+	}
+
+	if ntsd.Header.OffsetDacl != 0 {
+		dacl, err := parseACLAt(ntsdBytes, ntsd.Header.OffsetDacl)
+		if err != nil {
+			return ntsd, err
+		}
+		ntsd.DACL = &dacl
+	}
+
+	if ntsd.Header.OffsetOwner != 0 {
+		ntsd.Owner, err = parseSIDAt(ntsdBytes, ntsd.Header.OffsetOwner)
+		if err != nil {
+			return ntsd, err
+		}
+	}
+
+	if ntsd.Header.OffsetGroup != 0 {
+		ntsd.Group, err = parseSIDAt(ntsdBytes, ntsd.Header.OffsetGroup)
+	}
+	return ntsd, err
+}
+
+// ToBuffer serializes an NtSecurityDescriptor to its self-relative
+// binary representation, laid out as the SACL, DACL, owner and group.
+// Offsets and the SelfRelative, DACLPresent and SACLPresent control bits
+// are computed from the descriptor's contents. A NULL DACL keeps the
+// DACLPresent bit it was parsed or built with
+func (s NtSecurityDescriptor) ToBuffer() (bytes.Buffer, error) {
+	header := s.Header
+	header.OffsetOwner, header.OffsetGroup, header.OffsetSacl, header.OffsetDacl = 0, 0, 0, 0
+	header.Control |= SelfRelative
+	if header.Revision == 0 {
+		header.Revision = 1
+	}
+
+	body := bytes.Buffer{}
+	appendSection := func(section bytes.Buffer, err error) (uint32, error) {
+		if err != nil {
+			return 0, err
+		}
+		offset := uint32(ntsdHeaderSize + body.Len())
+		body.Write(section.Bytes())
+		return offset, nil
+	}
+
+	var err error
+	if s.SACL.isPresent() {
+		header.Control |= SACLPresent
+		if header.OffsetSacl, err = appendSection(s.SACL.ToBuffer()); err != nil {
+			return bytes.Buffer{}, err
+		}
+	}
+	if s.DACL != nil {
+		header.Control |= DACLPresent
+		if header.OffsetDacl, err = appendSection(s.DACL.ToBuffer()); err != nil {
+			return bytes.Buffer{}, err
+		}
+	}
+	if s.Owner.String() != "" {
+		if header.OffsetOwner, err = appendSection(s.Owner.ToBuffer()); err != nil {
+			return bytes.Buffer{}, err
+		}
+	}
+	if s.Group.String() != "" {
+		if header.OffsetGroup, err = appendSection(s.Group.ToBuffer()); err != nil {
+			return bytes.Buffer{}, err
+		}
+	}
+
+	buf := bytes.Buffer{}
+	if err := binary.Write(&buf, binary.LittleEndian, header); err != nil {
+		return buf, err
+	}
+	buf.Write(body.Bytes())
+	return buf, nil
+}
+
+// daclAces returns the ACEs of the DACL, none for a NULL DACL
+func (s NtSecurityDescriptor) daclAces() []ACE {
+	if s.DACL == nil {
+		return nil
+	}
+	return s.DACL.Aces
+}
+
+// ntsdHeaderSize is the size of a self-relative descriptor's header
+const ntsdHeaderSize = 20
+
+type NtSecurityDescriptorInvalidError struct{ msg string }
+
+func (e NtSecurityDescriptorInvalidError) Error() string {
+	return fmt.Sprintf("NewNtSecurityDescriptor: %s", e.msg)
+}
 
-	ntsd.DACL, err = NewACL(buf)
+// sectionAt returns a buffer positioned at offset, checking that at
+// least minSize bytes follow it
+func sectionAt(ntsdBytes []byte, offset uint32, minSize int) (*bytes.Buffer, error) {
+	if offset < ntsdHeaderSize || int(offset)+minSize > len(ntsdBytes) {
+		return nil, NtSecurityDescriptorInvalidError{fmt.Sprintf("offset %d is out of bounds", offset)}
+	}
+	return bytes.NewBuffer(ntsdBytes[offset:]), nil
+}
+
+func parseACLAt(ntsdBytes []byte, offset uint32) (ACL, error) {
+	buf, err := sectionAt(ntsdBytes, offset, 8)
 	if err != nil {
-		return ntsd, err
+		return ACL{}, err
 	}
+	return NewACL(buf)
+}
 
-	sidSize := ntsd.Header.OffsetGroup - ntsd.Header.OffsetOwner
-	ntsd.Owner, err = NewSID(buf, int(sidSize))
+func parseSIDAt(ntsdBytes []byte, offset uint32) (SID, error) {
+	buf, err := sectionAt(ntsdBytes, offset, 8)
 	if err != nil {
-		return ntsd, err
+		return SID{}, err
 	}
-	ntsd.Group, err = NewSID(buf, int(sidSize))
-	return ntsd, err
+
+	sidLength := 8 + 4*int(ntsdBytes[offset+1])
+	if sidLength > buf.Len() {
+		return SID{}, NtSecurityDescriptorInvalidError{fmt.Sprintf("SID at offset %d is truncated", offset)}
+	}
+	return NewSID(buf, sidLength)
 }
@@ -1,6 +1,8 @@
 package winacl_test
 
 import (
+	"encoding/binary"
+	"strings"
 	"testing"
 
 	winacl "github.com/kgoins/go-winacl/pkg"
@@ -29,6 +31,82 @@ func TestNewNtSecurityDescriptor(t *testing.T) {
 		r.Error(err)
 	})
 
+	t.Run("Returns an error when an offset is out of bounds", func(t *testing.T) {
+		ntsdBytes, err := getTestNtsdBytes()
+		r.NoError(err)
+
+		binary.LittleEndian.PutUint32(ntsdBytes[4:8], uint32(len(ntsdBytes)))
+		_, err = winacl.NewNtSecurityDescriptor(ntsdBytes)
+		r.Error(err)
+	})
+
+	t.Run("Reads the owner, group and ACLs from their offsets", func(t *testing.T) {
+		ntsdBytes, err := getTestNtsdBytes()
+		r.NoError(err)
+		expected := newTestSD()
+
+		// Move the owner and group ahead of the DACL
+		daclSize := int(expected.DACL.Header.Size)
+		sids := ntsdBytes[expected.Header.OffsetOwner:]
+		reordered := append([]byte{}, ntsdBytes[:20]...)
+		reordered = append(reordered, sids...)
+		reordered = append(reordered, ntsdBytes[20:20+daclSize]...)
+
+		binary.LittleEndian.PutUint32(reordered[4:8], 20)
+		binary.LittleEndian.PutUint32(reordered[8:12], 20+expected.Header.OffsetGroup-expected.Header.OffsetOwner)
+		binary.LittleEndian.PutUint32(reordered[16:20], uint32(20+len(sids)))
+
+		ntsd, err := winacl.NewNtSecurityDescriptor(reordered)
+		r.NoError(err)
+		r.Equal(expected.Owner, ntsd.Owner)
+		r.Equal(expected.Group, ntsd.Group)
+		r.Equal(expected.DACL, ntsd.DACL)
+	})
+
+}
+
+func TestNtSecurityDescriptorToBuffer(t *testing.T) {
+
+	r := require.New(t)
+
+	t.Run("Serializes a parsed Security Descriptor back to the same bytes", func(t *testing.T) {
+		ntsdBytes, err := getTestNtsdBytes()
+		r.NoError(err)
+
+		buf, err := newTestSD().ToBuffer()
+		r.NoError(err)
+		r.Equal(ntsdBytes, buf.Bytes())
+	})
+
+	t.Run("Preserves the difference between NULL and empty DACLs", func(t *testing.T) {
+		builder := func() *winacl.DescriptorBuilder {
+			return winacl.NewDescriptor().Control(winacl.DACLPresent)
+		}
+		nullDACL, err := builder().Build()
+		r.NoError(err)
+		emptyDACL, err := builder().EmptyDACL().Build()
+		r.NoError(err)
+
+		for _, ntsd := range []winacl.NtSecurityDescriptor{nullDACL, emptyDACL} {
+			buf, err := ntsd.ToBuffer()
+			r.NoError(err)
+
+			parsed, err := winacl.NewNtSecurityDescriptor(buf.Bytes())
+			r.NoError(err)
+			r.NotZero(parsed.Header.Control & winacl.DACLPresent)
+			r.Equal(ntsd.DACL, parsed.DACL)
+		}
+
+		r.Nil(nullDACL.DACL)
+		r.Zero(nullDACL.Header.OffsetDacl)
+		r.True(strings.HasSuffix(nullDACL.ToSDDL(), "G:D:NO_ACCESS_CONTROL"))
+
+		r.NotNil(emptyDACL.DACL)
+		r.Empty(emptyDACL.DACL.Aces)
+		r.Equal(uint16(8), emptyDACL.DACL.Header.Size)
+		r.True(strings.HasSuffix(emptyDACL.ToSDDL(), "G:D:"))
+	})
+
 }
 
 func TestToSDDL(t *testing.T) {
@@ -3,26 +3,76 @@ package winacl
 import (
 	"bytes"
 	"encoding/binary"
+	"strings"
+
+	"github.com/audibleblink/bamflags"
 )
 
 // NtSecurityDescriptorHeader is the Header of a Security Descriptor
 type NtSecurityDescriptorHeader struct {
 	Revision    byte
 	Sbz1        byte
-	Control     uint16
+	Control     ControlFlags
 	OffsetOwner uint32
 	OffsetGroup uint32
 	OffsetSacl  uint32
 	OffsetDacl  uint32
 }
 
+// ControlFlags is a type representing the SE_* bits of a Security
+// Descriptor's Control
+type ControlFlags uint16
+
 const (
-	DACLAutoInheritReq = 0x0100
-	DACLAutoInherited  = 0x0400
-	SACLAutoInherited  = 0x0800
-	DACLProtected      = 0x1000
+	OwnerDefaulted     ControlFlags = 0x0001
+	GroupDefaulted     ControlFlags = 0x0002
+	DACLPresent        ControlFlags = 0x0004
+	DACLDefaulted      ControlFlags = 0x0008
+	SACLPresent        ControlFlags = 0x0010
+	SACLDefaulted      ControlFlags = 0x0020
+	DACLTrusted        ControlFlags = 0x0040
+	ServerSecurity     ControlFlags = 0x0080
+	DACLAutoInheritReq ControlFlags = 0x0100
+	SACLAutoInheritReq ControlFlags = 0x0200
+	DACLAutoInherited  ControlFlags = 0x0400
+	SACLAutoInherited  ControlFlags = 0x0800
+	DACLProtected      ControlFlags = 0x1000
+	SACLProtected      ControlFlags = 0x2000
+	RMControlValid     ControlFlags = 0x4000
+	SelfRelative       ControlFlags = 0x8000
 )
 
+// ControlFlagsLookup maps ControlFlags to a human-readable labels
+var ControlFlagsLookup = map[ControlFlags]string{
+	OwnerDefaulted:     "SE_OWNER_DEFAULTED",
+	GroupDefaulted:     "SE_GROUP_DEFAULTED",
+	DACLPresent:        "SE_DACL_PRESENT",
+	DACLDefaulted:      "SE_DACL_DEFAULTED",
+	SACLPresent:        "SE_SACL_PRESENT",
+	SACLDefaulted:      "SE_SACL_DEFAULTED",
+	DACLTrusted:        "SE_DACL_TRUSTED",
+	ServerSecurity:     "SE_SERVER_SECURITY",
+	DACLAutoInheritReq: "SE_DACL_AUTO_INHERIT_REQ",
+	SACLAutoInheritReq: "SE_SACL_AUTO_INHERIT_REQ",
+	DACLAutoInherited:  "SE_DACL_AUTO_INHERITED",
+	SACLAutoInherited:  "SE_SACL_AUTO_INHERITED",
+	DACLProtected:      "SE_DACL_PROTECTED",
+	SACLProtected:      "SE_SACL_PROTECTED",
+	RMControlValid:     "SE_RM_CONTROL_VALID",
+	SelfRelative:       "SE_SELF_RELATIVE",
+}
+
+// String returns an human-readable representation of ControlFlags,
+// from the lowest bit to the highest
+func (f ControlFlags) String() string {
+	var readableFlags []string
+	flags, _ := bamflags.ParseInt(int64(f))
+	for _, flag := range flags {
+		readableFlags = append(readableFlags, ControlFlagsLookup[ControlFlags(flag)])
+	}
+	return strings.Join(readableFlags, " ")
+}
+
 // NewNTSDHeader is a constructor that will parse out an
 // NtSecurityDescriptorHeader from a byte buffer
 func NewNTSDHeader(buf *bytes.Buffer) (header NtSecurityDescriptorHeader, err error) {
@@ -0,0 +1,57 @@
+package winacl_test
+
+import (
+	"strings"
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControlFlags(t *testing.T) {
+
+	r := require.New(t)
+
+	t.Run("Names every set bit", func(t *testing.T) {
+		r.Equal(
+			"SE_DACL_PRESENT SE_DACL_AUTO_INHERITED SE_SACL_AUTO_INHERITED SE_SELF_RELATIVE",
+			newTestSD().Header.Control.String(),
+		)
+		r.Equal("SE_OWNER_DEFAULTED SE_DACL_TRUSTED SE_RM_CONTROL_VALID", (winacl.OwnerDefaulted | winacl.DACLTrusted | winacl.RMControlValid).String())
+		r.Equal("", winacl.ControlFlags(0).String())
+	})
+
+	t.Run("Splits SDDL flags between the DACL and the SACL", func(t *testing.T) {
+		control := winacl.DACLAutoInherited | winacl.DACLProtected | winacl.DACLAutoInheritReq | winacl.SACLAutoInherited
+		r.Equal("PARAI", control.DACLSDDL())
+		r.Equal("AI", control.SACLSDDL())
+		r.Equal("P", (winacl.SACLProtected | winacl.SelfRelative).SACLSDDL())
+	})
+
+	t.Run("Writes the SACL and NULL ACLs", func(t *testing.T) {
+		everyone, _ := winacl.ParseSID("S-1-1-0")
+		ntsd, err := winacl.NewDescriptor().
+			Control(winacl.SACLProtected|winacl.SACLAutoInherited).
+			Audit(everyone, winacl.AccessMaskWriteDACL, winacl.ACEHeaderFlagsSuccessfulAccessAceFlag).
+			Build()
+		r.NoError(err)
+		r.True(strings.HasSuffix(ntsd.ToSDDL(), "D:NO_ACCESS_CONTROLS:PAI(AU;SA;WD;;;WD)"))
+
+		ntsd.SACL = winacl.ACL{}
+		r.True(strings.HasSuffix(ntsd.ToSDDL(), "D:NO_ACCESS_CONTROLS:PAINO_ACCESS_CONTROL"))
+
+		ntsd.Header.Control &^= winacl.SACLPresent
+		r.True(strings.HasSuffix(ntsd.ToSDDL(), "D:NO_ACCESS_CONTROL"))
+	})
+}
+
+func TestDeprecatedControlSDDL(t *testing.T) {
+
+	r := require.New(t)
+
+	var control uint16 = winacl.ControlDACLProtected | winacl.ControlDACLAutoInherit
+	r.Equal(uint16(winacl.DACLProtected|winacl.DACLAutoInherited), control)
+	r.Equal("AR", winacl.NtSecurityDescriptorHeaderSDDL[winacl.ControlDACLAutoInheritReq])
+	r.Len(winacl.NtSecurityDescriptorHeaderSDDL, len(winacl.DACLControlSDDL))
+
+}
@@ -0,0 +1,271 @@
+package winacl
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PropertySetAttributeGUIDs maps the rightsGuid of the default property
+// sets to the schemaIDGUIDs of their member attributes, i.e. the
+// attributes whose attributeSecurityGUID names the property set. Loading
+// a schema export into a GUIDResolver supersedes this table for the
+// attributes it contains. The members of Domain-Other-Parameters,
+// Email-Information, General-Information, Public-Information and
+// Terminal-Server-License-Server are only known from a loaded schema
+//
+// https://docs.microsoft.com/en-us/windows/win32/adschema/property-sets
+var PropertySetAttributeGUIDs = map[string][]string{
+	// Domain-Password
+	"c7407360-20bf-11d0-a768-00aa006e0529": {
+		"bf9679a4-0de6-11d0-a285-00aa003049e2", // Lock-Out-Observation-Window
+		"bf9679a5-0de6-11d0-a285-00aa003049e2", // Lockout-Duration
+		"bf9679a6-0de6-11d0-a285-00aa003049e2", // Lockout-Threshold
+		"bf9679bb-0de6-11d0-a285-00aa003049e2", // Max-Pwd-Age
+		"bf9679c2-0de6-11d0-a285-00aa003049e2", // Min-Pwd-Age
+		"bf9679c3-0de6-11d0-a285-00aa003049e2", // Min-Pwd-Length
+		"bf967a09-0de6-11d0-a285-00aa003049e2", // Pwd-History-Length
+		"bf967a0b-0de6-11d0-a285-00aa003049e2", // Pwd-Properties
+	},
+	// Membership
+	"bc0ac240-79a9-11d0-9020-00c04fc2d4cf": {
+		"bf9679c0-0de6-11d0-a285-00aa003049e2", // Member
+		"bf967991-0de6-11d0-a285-00aa003049e2", // Is-Member-Of-DL
+	},
+	// Personal-Information
+	"77b5b886-944a-11d1-aebd-0000f80367c1": {
+		"f0f8ff84-1191-11d0-a060-00aa006c33ed", // Address
+		"16775781-47f3-11d1-a9c3-0000f80367c1", // Address-Home
+		"0296c11c-40da-11d1-a9c0-0000f80367c1", // Assistant
+		"bf96793e-0de6-11d0-a285-00aa003049e2", // Comment
+		"bf967974-0de6-11d0-a285-00aa003049e2", // Facsimile-Telephone-Number
+		"bf96798d-0de6-11d0-a285-00aa003049e2", // International-ISDN-Number
+		"bf9679a2-0de6-11d0-a285-00aa003049e2", // Locality-Name
+		"0296c11d-40da-11d1-a9c0-0000f80367c1", // Phone-Fax-Other
+		"f0f8ffa2-1191-11d0-a060-00aa006c33ed", // Phone-Home-Other
+		"f0f8ffa1-1191-11d0-a060-00aa006c33ed", // Phone-Home-Primary
+		"4d146e4b-48d4-11d1-a9c3-0000f80367c1", // Phone-Ip-Other
+		"4d146e4a-48d4-11d1-a9c3-0000f80367c1", // Phone-Ip-Primary
+		"0296c11f-40da-11d1-a9c0-0000f80367c1", // Phone-ISDN-Primary
+		"0296c11e-40da-11d1-a9c0-0000f80367c1", // Phone-Mobile-Other
+		"f0f8ffa3-1191-11d0-a060-00aa006c33ed", // Phone-Mobile-Primary
+		"f0f8ffa5-1191-11d0-a060-00aa006c33ed", // Phone-Office-Other
+		"f0f8ffa4-1191-11d0-a060-00aa006c33ed", // Phone-Pager-Other
+		"f0f8ffa6-1191-11d0-a060-00aa006c33ed", // Phone-Pager-Primary
+		"bf9679fb-0de6-11d0-a285-00aa003049e2", // Post-Office-Box
+		"bf9679fc-0de6-11d0-a285-00aa003049e2", // Postal-Address
+		"bf9679fd-0de6-11d0-a285-00aa003049e2", // Postal-Code
+		"bf9679fe-0de6-11d0-a285-00aa003049e2", // Preferred-Delivery-Method
+		"bf967a10-0de6-11d0-a285-00aa003049e2", // Registered-Address
+		"bf967a39-0de6-11d0-a285-00aa003049e2", // State-Or-Province-Name
+		"bf967a3a-0de6-11d0-a285-00aa003049e2", // Street-Address
+		"bf967a49-0de6-11d0-a285-00aa003049e2", // Telephone-Number
+		"bf967a4a-0de6-11d0-a285-00aa003049e2", // Teletex-Terminal-Identifier
+		"bf967a4b-0de6-11d0-a285-00aa003049e2", // Telex-Number
+		"0296c121-40da-11d1-a9c0-0000f80367c1", // Telex-Primary
+		"e16a9db2-403c-11d1-a9c0-0000f80367c1", // User-SMIME-Certificate
+		"bf967a7b-0de6-11d0-a285-00aa003049e2", // X121-Address
+		"bf967a7f-0de6-11d0-a285-00aa003049e2", // X509-Cert
+	},
+	// Private-Information
+	"91e647de-d96f-4b70-9557-d63ff4f3ccd8": {
+		"b8dfa744-31dc-4ef1-ac7c-84baf7ef9da7", // ms-PKI-AccountCredentials
+		"b7ff5a38-0818-42b0-8110-d3d154c97f24", // ms-PKI-Credential-Roaming-Tokens
+		"b3f93023-9239-4f7c-b99c-6745d87adbc2", // ms-PKI-DPAPIMasterKeys
+		"6617e4ac-a2f1-43ab-b60c-11fbd1facf05", // ms-PKI-RoamingTimeStamp
+	},
+	// RAS-Information
+	"037088f8-0ae1-11d2-b422-00a0c968f939": {
+		"db0c9085-c1f2-11d1-bbc5-0080c76670c0", // msNPAllowDialin
+		"db0c908a-c1f2-11d1-bbc5-0080c76670c0", // msNPCallingStationID
+		"db0c909c-c1f2-11d1-bbc5-0080c76670c0", // msRADIUSCallbackNumber
+		"db0c90a4-c1f2-11d1-bbc5-0080c76670c0", // msRADIUSFramedIPAddress
+		"db0c90a9-c1f2-11d1-bbc5-0080c76670c0", // msRADIUSFramedRoute
+		"db0c90b6-c1f2-11d1-bbc5-0080c76670c0", // msRADIUSServiceType
+		"db0c90c5-c1f2-11d1-bbc5-0080c76670c0", // msRASSavedCallbackNumber
+		"db0c90c6-c1f2-11d1-bbc5-0080c76670c0", // msRASSavedFramedIPAddress
+		"db0c90c7-c1f2-11d1-bbc5-0080c76670c0", // msRASSavedFramedRoute
+	},
+	// User-Account-Restrictions
+	"4c164200-20c0-11d0-a768-00aa006e0529": {
+		"bf967915-0de6-11d0-a285-00aa003049e2", // Account-Expires
+		"bf967a0a-0de6-11d0-a285-00aa003049e2", // Pwd-Last-Set
+		"bf967a68-0de6-11d0-a285-00aa003049e2", // User-Account-Control
+		"bf967a6d-0de6-11d0-a285-00aa003049e2", // User-Parameters
+	},
+	// User-Logon
+	"5f202010-79a5-11d0-9020-00c04fc2d4cf": {
+		"bf96792d-0de6-11d0-a285-00aa003049e2", // Bad-Password-Time
+		"bf96792e-0de6-11d0-a285-00aa003049e2", // Bad-Pwd-Count
+		"bf967985-0de6-11d0-a285-00aa003049e2", // Home-Directory
+		"bf967986-0de6-11d0-a285-00aa003049e2", // Home-Drive
+		"bf967996-0de6-11d0-a285-00aa003049e2", // Last-Logoff
+		"bf967997-0de6-11d0-a285-00aa003049e2", // Last-Logon
+		"c0e20a04-0e5a-4ff3-9482-5efeaecd7060", // Last-Logon-Timestamp
+		"bf9679aa-0de6-11d0-a285-00aa003049e2", // Logon-Count
+		"bf9679ab-0de6-11d0-a285-00aa003049e2", // Logon-Hours
+		"bf9679ac-0de6-11d0-a285-00aa003049e2", // Logon-Workstation
+		"bf967a05-0de6-11d0-a285-00aa003049e2", // Profile-Path
+		"bf9679a8-0de6-11d0-a285-00aa003049e2", // Script-Path
+	},
+	// Web-Information
+	"e45795b3-9455-11d1-aebd-0000f80367c1": {
+		"bf967a7a-0de6-11d0-a285-00aa003049e2", // WWW-Home-Page
+		"9a9a0221-4a5b-11d1-a9c3-0000f80367c1", // WWW-Page-Other
+	},
+}
+
+// sharedAttributeNames names the attributes whose schemaIDGUID is shared
+// with a validated write, and so resolves to the right rather than the
+// attribute in the built-in tables
+var sharedAttributeNames = map[string]string{
+	"bf9679c0-0de6-11d0-a285-00aa003049e2": "Member",
+}
+
+// builtinPropertySetOf maps attribute GUIDs to their property set GUID
+var builtinPropertySetOf = newBuiltinPropertySetOf()
+
+func newBuiltinPropertySetOf() map[string]string {
+	setOf := make(map[string]string)
+	for set, attrs := range PropertySetAttributeGUIDs {
+		for _, attr := range attrs {
+			setOf[attr] = set
+		}
+	}
+	return setOf
+}
+
+// PropertySet returns the property set an attribute belongs to
+func (r *GUIDResolver) PropertySet(attr GUID) (GUIDInfo, bool) {
+	set, found := r.propertySetGUIDOf(attr.String())
+	if !found {
+		return GUIDInfo{}, false
+	}
+
+	setGUID, err := ParseGUID(set)
+	if err != nil {
+		return GUIDInfo{}, false
+	}
+	return r.Lookup(setGUID)
+}
+
+// PropertySetMembers returns the attributes belonging to a property set,
+// sorted by name. It returns an UnknownPropertySetMembersError when
+// neither the built-in tables nor the loaded schema list any member, as
+// an empty result would hide every right granted through the set
+func (r *GUIDResolver) PropertySetMembers(set GUID) ([]GUIDInfo, error) {
+	setStr := set.String()
+	candidates := make(map[string]bool)
+
+	for _, attr := range PropertySetAttributeGUIDs[setStr] {
+		candidates[attr] = true
+	}
+	if r != nil {
+		for attr := range r.propertySetOf {
+			candidates[attr] = true
+		}
+	}
+
+	members := []GUIDInfo{}
+	for attr := range candidates {
+		if memberOf, _ := r.propertySetGUIDOf(attr); memberOf != setStr {
+			continue
+		}
+		if guid, err := ParseGUID(attr); err == nil {
+			members = append(members, r.attributeInfo(guid))
+		}
+	}
+
+	if len(members) == 0 {
+		return members, UnknownPropertySetMembersError{fmt.Sprintf("the members of %s are unknown; load a schema export", r.Resolve(set))}
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Name < members[j].Name
+	})
+	return members, nil
+}
+
+type UnknownPropertySetMembersError struct{ msg string }
+
+func (e UnknownPropertySetMembersError) Error() string {
+	return fmt.Sprintf("PropertySetMembers: %s", e.msg)
+}
+
+// ExpandAttributes returns the concrete attributes whose properties are
+// covered by an object ACE: the members of its ObjectType when that is a
+// property set, or the ObjectType itself when it is an attribute. all is
+// true when the ACE carries no ObjectType and so covers every attribute.
+// Classes and control access rights cover no attributes. Property sets
+// whose members are unknown return an UnknownPropertySetMembersError
+func (r *GUIDResolver) ExpandAttributes(aa AdvancedAce) (attrs []GUIDInfo, all bool, err error) {
+	if aa.Flags&ACEInheritanceFlagsObjectTypePresent == 0 {
+		return nil, true, nil
+	}
+
+	info, found := r.Lookup(aa.ObjectType)
+	if !found {
+		return []GUIDInfo{r.attributeInfo(aa.ObjectType)}, false, nil
+	}
+
+	switch info.Kind {
+	case GUIDKindPropertySet:
+		attrs, err = r.PropertySetMembers(aa.ObjectType)
+		return attrs, false, err
+	case GUIDKindAttribute, GUIDKindValidatedWrite:
+		return []GUIDInfo{r.attributeInfo(aa.ObjectType)}, false, nil
+	}
+	return nil, false, nil
+}
+
+// CoversAttribute reports whether the property rights of an object ACE
+// apply to the given attribute, either directly or through the property
+// set the attribute belongs to. When the ObjectType is a property set
+// whose members are unknown and the attribute's own set is unknown too,
+// it returns an UnknownPropertySetMembersError rather than a false negative
+func (r *GUIDResolver) CoversAttribute(aa AdvancedAce, attr GUID) (bool, error) {
+	if aa.Flags&ACEInheritanceFlagsObjectTypePresent == 0 {
+		return true, nil
+	}
+	if aa.ObjectType == attr {
+		return true, nil
+	}
+
+	if set, found := r.propertySetGUIDOf(attr.String()); found {
+		return set == aa.ObjectType.String(), nil
+	}
+
+	info, found := r.Lookup(aa.ObjectType)
+	if !found || info.Kind != GUIDKindPropertySet {
+		return false, nil
+	}
+	if _, err := r.PropertySetMembers(aa.ObjectType); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+func (r *GUIDResolver) propertySetGUIDOf(attr string) (string, bool) {
+	if r != nil {
+		if set, found := r.propertySetOf[attr]; found {
+			return set, true
+		}
+	}
+	set, found := builtinPropertySetOf[attr]
+	return set, found
+}
+
+// attributeInfo describes a GUID as an attribute, even if it is unknown
+// or shared with a validated write
+func (r *GUIDResolver) attributeInfo(attr GUID) GUIDInfo {
+	info, found := r.Lookup(attr)
+	if !found {
+		info = GUIDInfo{GUID: attr, Name: attr.String()}
+	}
+	if name, shared := sharedAttributeNames[attr.String()]; shared && info.Kind != GUIDKindAttribute {
+		info.Name = name
+	}
+
+	info.Kind = GUIDKindAttribute
+	info.ValidAccesses = attributeValidAccesses
+	return info
+}
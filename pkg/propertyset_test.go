@@ -0,0 +1,100 @@
+package winacl_test
+
+import (
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPropertySetMembers(t *testing.T) {
+
+	r := require.New(t)
+
+	personalInformation := winacl.GUID{
+		Data1: 0x77b5b886, Data2: 0x944a, Data3: 0x11d1,
+		Data4: [8]byte{0xae, 0xbd, 0x00, 0x00, 0xf8, 0x03, 0x67, 0xc1},
+	}
+	telephoneNumber := winacl.GUID{
+		Data1: 0xbf967a49, Data2: 0x0de6, Data3: 0x11d0,
+		Data4: [8]byte{0xa2, 0x85, 0x00, 0xaa, 0x00, 0x30, 0x49, 0xe2},
+	}
+	badgeInformation := winacl.GUID{
+		Data1: 0x0f4a8c2d, Data2: 0x6e1b, Data3: 0x4a3f,
+		Data4: [8]byte{0x9c, 0x7d, 0x8e, 0x2b, 0x5a, 0x1f, 0x6d, 0x33},
+	}
+
+	t.Run("Knows the members of the built-in property sets", func(t *testing.T) {
+		var resolver *winacl.GUIDResolver
+		members, err := resolver.PropertySetMembers(personalInformation)
+		r.NoError(err)
+		r.Contains(members, winacl.GUIDInfo{
+			GUID:          telephoneNumber,
+			Name:          "Telephone-Number",
+			Kind:          winacl.GUIDKindAttribute,
+			ValidAccesses: winacl.ADSRightDSReadProp | winacl.ADSRightDSWriteProp,
+		})
+
+		set, found := resolver.PropertySet(telephoneNumber)
+		r.True(found)
+		r.Equal("Personal-Information", set.Name)
+	})
+
+	t.Run("Uses attributeSecurityGUID from a loaded schema", func(t *testing.T) {
+		resolver, err := newTestGUIDResolver()
+		r.NoError(err)
+
+		members, err := resolver.PropertySetMembers(badgeInformation)
+		r.NoError(err)
+		r.Len(members, 1)
+		r.Equal("corpBadgeNumber", members[0].Name)
+	})
+
+	t.Run("Expands an object ACE into the attributes it covers", func(t *testing.T) {
+		var resolver *winacl.GUIDResolver
+		for _, ace := range newTestSD().DACL.Aces {
+			aa, ok := ace.ObjectAce.(winacl.AdvancedAce)
+			if !ok || aa.ObjectType != personalInformation {
+				continue
+			}
+
+			attrs, all, err := resolver.ExpandAttributes(aa)
+			r.NoError(err)
+			r.False(all)
+			r.NotEmpty(attrs)
+			covered, err := resolver.CoversAttribute(aa, telephoneNumber)
+			r.NoError(err)
+			r.True(covered)
+		}
+
+		attrs, all, err := resolver.ExpandAttributes(winacl.AdvancedAce{})
+		r.NoError(err)
+		r.True(all)
+		r.Empty(attrs)
+	})
+
+	t.Run("Reports property sets whose members are unknown", func(t *testing.T) {
+		var resolver *winacl.GUIDResolver
+		publicInformation, err := winacl.ParseGUID("e48d0154-bcf8-11d1-8702-00c04fb96050")
+		r.NoError(err)
+
+		_, err = resolver.PropertySetMembers(publicInformation)
+		r.IsType(winacl.UnknownPropertySetMembersError{}, err)
+		r.Contains(err.Error(), "Public-Information")
+
+		aa := winacl.AdvancedAce{Flags: winacl.ACEInheritanceFlagsObjectTypePresent, ObjectType: publicInformation}
+		_, all, err := resolver.ExpandAttributes(aa)
+		r.False(all)
+		r.Error(err)
+
+		covered, err := resolver.CoversAttribute(aa, telephoneNumber)
+		r.NoError(err)
+		r.False(covered)
+
+		unlisted := winacl.GUID{Data1: 0x1}
+		covered, err = resolver.CoversAttribute(aa, unlisted)
+		r.False(covered)
+		r.IsType(winacl.UnknownPropertySetMembersError{}, err)
+	})
+
+}
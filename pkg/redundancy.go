@@ -0,0 +1,501 @@
+package winacl
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// Kinds of redundant ACEs
+const (
+	// RedundancyDuplicate is an ACE identical to an earlier one
+	RedundancyDuplicate = "duplicate"
+	// RedundancySubsumed is an ACE whose rights were already granted,
+	// denied or audited by earlier ACEs of the same kind
+	RedundancySubsumed = "subsumed"
+	// RedundancyShadowed is an ACE whose rights were already decided
+	// the other way by earlier ACEs, e.g. an allow after a deny
+	RedundancyShadowed = "shadowed"
+)
+
+// everyoneSID is held by every token, so its ACEs cover any trustee
+const everyoneSID = "S-1-1-0"
+
+// RedundantACE is an ACE that can never affect an access decision or
+// an audit, because the earlier ACEs listed in CoveredBy already decide
+// all of its rights for the same or a broader principal and scope
+type RedundantACE struct {
+	Index     int    `json:"index"`
+	ACE       ACE    `json:"ace"`
+	Kind      string `json:"kind"`
+	CoveredBy []int  `json:"coveredBy"`
+	Reason    string `json:"reason"`
+}
+
+// RedundancyAnalysis finds redundant ACEs. An ACE for a group covers the
+// ACEs of its members when Membership holds the group's members, and
+// ACEs for Everyone cover every trustee. Mapping is used to expand
+// GENERIC_* rights and defaults to ADGenericMapping. Owner is the
+// descriptor's owner, checked by Simplify with and without the implicit
+// rights OWNER RIGHTS ACEs take away; when unset every trustee is also
+// checked as the owner
+type RedundancyAnalysis struct {
+	Membership *Membership
+	Mapping    GenericMapping
+	Owner      SID
+}
+
+// RedundantAces returns the ACL's redundant ACEs, expanding GENERIC_*
+// rights as AD does
+func (a ACL) RedundantAces() []RedundantACE {
+	return RedundancyAnalysis{}.RedundantAces(a)
+}
+
+// Simplify returns a copy of the ACL without its redundant ACEs, as
+// RedundancyAnalysis.Simplify does
+func (a ACL) Simplify() (ACL, []RedundantACE, error) {
+	return RedundancyAnalysis{}.Simplify(a)
+}
+
+// RedundantAces returns the ACL's redundant ACEs in order. An earlier
+// ACE covers a later one when it applies to every token, object type and
+// inheritance scope the later one does. Callback ACEs are left alone as
+// their conditions cannot be evaluated, and so are OWNER RIGHTS ACEs
+// unless an earlier one applies wherever they do, since removing the
+// last one restores the owner's implicit READ_CONTROL and WRITE_DAC
+func (ra RedundancyAnalysis) RedundantAces(acl ACL) []RedundantACE {
+	mapping := ra.mapping()
+	redundant := []RedundantACE{}
+
+	for idx, ace := range acl.Aces {
+		if !isAnalyzableACE(ace) {
+			continue
+		}
+		mask := mapping.Map(ace.AccessMask.Raw())
+		if mask == 0 {
+			continue
+		}
+
+		var covered uint32
+		coveredBy := []int{}
+		duplicateOf := -1
+		shadowed := false
+		for prev := 0; prev < idx; prev++ {
+			earlier := acl.Aces[prev]
+			if !isAnalyzableACE(earlier) || !ra.covers(earlier, ace) {
+				continue
+			}
+			overlap := mapping.Map(earlier.AccessMask.Raw()) & mask
+			if overlap == 0 {
+				continue
+			}
+
+			covered |= overlap
+			coveredBy = append(coveredBy, prev)
+			if isDenyACE(earlier) != isDenyACE(ace) {
+				shadowed = true
+			}
+			if duplicateOf < 0 && sameACE(earlier, ace) {
+				duplicateOf = prev
+			}
+		}
+		if covered != mask {
+			continue
+		}
+		if isOwnerRightsACE(ace) && !ownerRightsCovered(acl.Aces[:idx], ace) {
+			continue
+		}
+
+		entry := RedundantACE{Index: idx, ACE: ace, CoveredBy: coveredBy}
+		switch {
+		case duplicateOf >= 0:
+			entry.Kind = RedundancyDuplicate
+			entry.CoveredBy = []int{duplicateOf}
+			entry.Reason = fmt.Sprintf("duplicate of ACE %d", duplicateOf)
+		case shadowed:
+			entry.Kind = RedundancyShadowed
+			entry.Reason = fmt.Sprintf("rights already %s by %s", decidedVerb(acl, coveredBy), aceIndexList(coveredBy))
+		default:
+			entry.Kind = RedundancySubsumed
+			entry.Reason = fmt.Sprintf("rights already %s by %s", decidedVerb(acl, coveredBy), aceIndexList(coveredBy))
+		}
+		redundant = append(redundant, entry)
+	}
+
+	return redundant
+}
+
+// Simplify returns a copy of the ACL without its redundant ACEs, along
+// with the ACEs removed. The simplified ACL is checked to grant, deny and
+// audit the same rights as the original for a token of every trustee,
+// for every object type and on the object, its children and
+// grandchildren; an error is returned if it does not
+func (ra RedundancyAnalysis) Simplify(acl ACL) (ACL, []RedundantACE, error) {
+	redundant := ra.RedundantAces(acl)
+
+	removed := make(map[int]bool)
+	for _, entry := range redundant {
+		removed[entry.Index] = true
+	}
+	kept := make([]ACE, 0, len(acl.Aces)-len(redundant))
+	for idx, ace := range acl.Aces {
+		if !removed[idx] {
+			kept = append(kept, ace)
+		}
+	}
+
+	simplified := ACL{Header: acl.Header}
+	if err := simplified.setAces(kept); err != nil {
+		return ACL{}, nil, err
+	}
+	if err := ra.checkEquivalent(acl, simplified); err != nil {
+		return ACL{}, nil, err
+	}
+	return simplified, redundant, nil
+}
+
+func (ra RedundancyAnalysis) mapping() GenericMapping {
+	if ra.Mapping == (GenericMapping{}) {
+		return ADGenericMapping
+	}
+	return ra.Mapping
+}
+
+// covers reports whether earlier applies wherever later does, leaving
+// the rights aside
+func (ra RedundancyAnalysis) covers(earlier ACE, later ACE) bool {
+	if isAuditACE(earlier) != isAuditACE(later) {
+		return false
+	}
+	if isAuditACE(later) {
+		outcomes := ACEHeaderFlags(ACEHeaderFlagsSuccessfulAccessAceFlag | ACEHeaderFlagsFailedAccessAceFlag)
+		if later.Header.Flags&outcomes&^earlier.Header.Flags != 0 {
+			return false
+		}
+	}
+
+	if !ra.principalCovers(earlier.ObjectAce.GetPrincipal(), later.ObjectAce.GetPrincipal()) {
+		return false
+	}
+
+	earlierType, earlierInherited := aceObjectTypes(earlier)
+	laterType, laterInherited := aceObjectTypes(later)
+	if !earlierType.IsNull() && earlierType != laterType {
+		return false
+	}
+	if !earlierInherited.IsNull() && earlierInherited != laterInherited {
+		return false
+	}
+
+	return scopeCovers(earlier.Header.Flags, later.Header.Flags)
+}
+
+// principalCovers reports whether every token holding sid also holds
+// broader
+func (ra RedundancyAnalysis) principalCovers(broader SID, sid SID) bool {
+	broaderStr, sidStr := broader.String(), sid.String()
+	if broaderStr == sidStr || broaderStr == everyoneSID {
+		return true
+	}
+	if ra.Membership == nil {
+		return false
+	}
+
+	seen := map[string]bool{sidStr: true}
+	queue := []string{sidStr}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for group := range ra.Membership.memberOf[current] {
+			if group == broaderStr {
+				return true
+			}
+			if !seen[group] {
+				seen[group] = true
+				queue = append(queue, group)
+			}
+		}
+	}
+	return false
+}
+
+// checkEquivalent compares the decisions of two ACLs for a token of every
+// trustee of the original, on every object type it names and on the
+// object, its children and grandchildren
+func (ra RedundancyAnalysis) checkEquivalent(original ACL, simplified ACL) error {
+	mapping := ra.mapping()
+
+	objectTypes := []GUID{{}}
+	classes := []GUID{{}}
+	tokens := []Token{}
+	seenTokens := make(map[string]bool)
+	for _, ace := range original.Aces {
+		if ace.ObjectAce == nil {
+			continue
+		}
+		objectType, inheritedObjectType := aceObjectTypes(ace)
+		if !containsGUID(objectTypes, objectType) {
+			objectTypes = append(objectTypes, objectType)
+		}
+		if !containsGUID(classes, inheritedObjectType) {
+			classes = append(classes, inheritedObjectType)
+		}
+
+		principal := ace.ObjectAce.GetPrincipal()
+		if seenTokens[principal.String()] {
+			continue
+		}
+		seenTokens[principal.String()] = true
+		tokens = append(tokens, ra.tokenFor(principal))
+	}
+	if ra.Owner.String() != "" && !seenTokens[ra.Owner.String()] {
+		tokens = append(tokens, ra.tokenFor(ra.Owner))
+	}
+
+	for _, scope := range inheritanceScopes(classes) {
+		before := scope.view(original.Aces)
+		after := scope.view(simplified.Aces)
+		for _, token := range tokens {
+			for _, owner := range ra.ownersFor(token) {
+				for _, objectType := range objectTypes {
+					if aclDecision(before, token, owner, mapping, objectType) != aclDecision(after, token, owner, mapping, objectType) {
+						return fmt.Errorf("ACL.Simplify: simplified ACL differs for %s on %s", token.User, scope)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ownersFor returns the owners a token is checked against: the
+// descriptor's owner when known, or else none and the token's user
+func (ra RedundancyAnalysis) ownersFor(token Token) []SID {
+	if ra.Owner.String() != "" {
+		return []SID{ra.Owner}
+	}
+	return []SID{{}, token.User}
+}
+
+func (ra RedundancyAnalysis) tokenFor(principal SID) Token {
+	if ra.Membership != nil {
+		return ra.Membership.NewToken(principal)
+	}
+	everyone, _ := ParseSID(everyoneSID)
+	return Token{User: principal, Groups: []SID{everyone}}
+}
+
+// inheritanceScope is the object an ACL is attached to, or one of its
+// children or grandchildren of a given class
+type inheritanceScope struct {
+	depth     int
+	container bool
+	class     GUID
+}
+
+func inheritanceScopes(classes []GUID) []inheritanceScope {
+	scopes := []inheritanceScope{{}}
+	for depth := 1; depth <= 2; depth++ {
+		for _, class := range classes {
+			scopes = append(scopes,
+				inheritanceScope{depth: depth, container: true, class: class},
+				inheritanceScope{depth: depth, container: false, class: class},
+			)
+		}
+	}
+	return scopes
+}
+
+func (s inheritanceScope) String() string {
+	kind := "leaf"
+	if s.container {
+		kind = "container"
+	}
+	switch s.depth {
+	case 0:
+		return "the object"
+	case 1:
+		return fmt.Sprintf("a child %s of class %s", kind, s.class)
+	default:
+		return fmt.Sprintf("a grandchild %s of class %s", kind, s.class)
+	}
+}
+
+// view returns the ACEs in effect on the scope's object, in order
+func (s inheritanceScope) view(aces []ACE) []ACE {
+	view := []ACE{}
+	for _, ace := range aces {
+		flags := ace.Header.Flags
+		if s.depth == 0 {
+			if flags&ACEHeaderFlagsInheritOnlyAce == 0 {
+				view = append(view, ace)
+			}
+			continue
+		}
+
+		if s.depth > 1 && flags&ACEHeaderFlagsNoPropogateInheritAce != 0 {
+			continue
+		}
+		if s.container && flags&ACEHeaderFlagsContainerInheritAce == 0 {
+			continue
+		}
+		if !s.container && flags&ACEHeaderFlagsObjectInheritAce == 0 {
+			continue
+		}
+		if _, inheritedObjectType := aceObjectTypes(ace); !inheritedObjectType.IsNull() && inheritedObjectType != s.class {
+			continue
+		}
+
+		ace.Header.Flags &^= ACEHeaderFlagsInheritOnlyAce
+		view = append(view, ace)
+	}
+	return view
+}
+
+// aclDecisionResult holds the rights granted and audited by a list of ACEs
+type aclDecisionResult struct {
+	granted      uint32
+	auditSuccess uint32
+	auditFailure uint32
+}
+
+func aclDecision(aces []ACE, token Token, owner SID, mapping GenericMapping, objectType GUID) aclDecisionResult {
+	var implicit uint32
+	if owner.String() != "" && token.HasSID(owner) && !hasOwnerRightsACE(aces) {
+		implicit = AccessMaskReadControl | AccessMaskWriteDACL
+	}
+	result := aclDecisionResult{
+		granted: implicit | evaluateDACL(ACL{Aces: aces}, token, owner, mapping, objectType, ^implicit),
+	}
+
+	for _, ace := range aces {
+		if !isAuditACE(ace) || !token.HasSID(ace.ObjectAce.GetPrincipal()) {
+			continue
+		}
+		if aceType, _ := aceObjectTypes(ace); !aceType.IsNull() && aceType != objectType {
+			continue
+		}
+		if ace.Header.Flags&ACEHeaderFlagsSuccessfulAccessAceFlag != 0 {
+			result.auditSuccess |= mapping.Map(ace.AccessMask.Raw())
+		}
+		if ace.Header.Flags&ACEHeaderFlagsFailedAccessAceFlag != 0 {
+			result.auditFailure |= mapping.Map(ace.AccessMask.Raw())
+		}
+	}
+	return result
+}
+
+// scopeCovers reports whether an ACE with the earlier flags applies to
+// the object and its descendants wherever one with the later flags does
+func scopeCovers(earlier ACEHeaderFlags, later ACEHeaderFlags) bool {
+	if later&ACEHeaderFlagsInheritOnlyAce == 0 && earlier&ACEHeaderFlagsInheritOnlyAce != 0 {
+		return false
+	}
+
+	inheritance := ACEHeaderFlags(ACEHeaderFlagsContainerInheritAce | ACEHeaderFlagsObjectInheritAce)
+	if later&inheritance&^earlier != 0 {
+		return false
+	}
+	if later&inheritance != 0 && later&ACEHeaderFlagsNoPropogateInheritAce == 0 &&
+		earlier&ACEHeaderFlagsNoPropogateInheritAce != 0 {
+		return false
+	}
+	return true
+}
+
+func isOwnerRightsACE(ace ACE) bool {
+	return ace.ObjectAce != nil && (isAllowACE(ace) || isDenyACE(ace)) &&
+		ace.ObjectAce.GetPrincipal().String() == ownerRightsSID
+}
+
+// ownerRightsCovered reports whether one of the earlier ACEs is an
+// OWNER RIGHTS ACE present on every object the later one is
+func ownerRightsCovered(earlier []ACE, later ACE) bool {
+	_, laterInherited := aceObjectTypes(later)
+	for _, ace := range earlier {
+		if !isOwnerRightsACE(ace) {
+			continue
+		}
+		if _, inherited := aceObjectTypes(ace); !inherited.IsNull() && inherited != laterInherited {
+			continue
+		}
+		if scopeCovers(ace.Header.Flags, later.Header.Flags) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAnalyzableACE reports whether an ACE is a parsed allow, deny or
+// audit ACE without a callback condition
+func isAnalyzableACE(ace ACE) bool {
+	if ace.ObjectAce == nil {
+		return false
+	}
+	switch ace.Header.Type {
+	case AceTypeAccessAllowed, AceTypeAccessAllowedObject,
+		AceTypeAccessDenied, AceTypeAccessDeniedObject,
+		AceTypeSystemAudit, AceTypeSystemAuditObject:
+		return true
+	}
+	return false
+}
+
+// aceObjectTypes returns the ObjectType and InheritedObjectType of an
+// ACE, using the null GUID for those that are absent
+func aceObjectTypes(ace ACE) (objectType GUID, inheritedObjectType GUID) {
+	aa, ok := ace.ObjectAce.(AdvancedAce)
+	if !ok {
+		return
+	}
+	if aa.Flags&ACEInheritanceFlagsObjectTypePresent != 0 {
+		objectType = aa.ObjectType
+	}
+	if aa.Flags&ACEInheritanceFlagsInheritedObjectTypePresent != 0 {
+		inheritedObjectType = aa.InheritedObjectType
+	}
+	return
+}
+
+func sameACE(a ACE, b ACE) bool {
+	aBuf, aErr := a.ToBuffer()
+	bBuf, bErr := b.ToBuffer()
+	return aErr == nil && bErr == nil && bytes.Equal(aBuf.Bytes(), bBuf.Bytes())
+}
+
+// decidedVerb describes what the covering ACEs did with the rights
+func decidedVerb(acl ACL, indexes []int) string {
+	allow, deny, audit := false, false, false
+	for _, idx := range indexes {
+		switch {
+		case isAllowACE(acl.Aces[idx]):
+			allow = true
+		case isDenyACE(acl.Aces[idx]):
+			deny = true
+		default:
+			audit = true
+		}
+	}
+
+	switch {
+	case audit:
+		return "audited"
+	case allow && deny:
+		return "granted or denied"
+	case deny:
+		return "denied"
+	default:
+		return "granted"
+	}
+}
+
+func aceIndexList(indexes []int) string {
+	if len(indexes) == 1 {
+		return "ACE " + strconv.Itoa(indexes[0])
+	}
+	strs := make([]string, 0, len(indexes))
+	for _, idx := range indexes {
+		strs = append(strs, strconv.Itoa(idx))
+	}
+	return "ACEs " + joinPhrases(strs)
+}
@@ -0,0 +1,122 @@
+package winacl_test
+
+import (
+	"testing"
+
+	winacl "github.com/kgoins/go-winacl/pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedundantAces(t *testing.T) {
+
+	r := require.New(t)
+
+	everyone, _ := winacl.ParseSID("S-1-1-0")
+	alice, _ := winacl.ParseSID(testDomainSID + "-1105")
+	helpdesk, _ := winacl.ParseSID(testDomainSID + "-1110")
+	personalInfo, _ := winacl.ParseGUID("77b5b886-944a-11d1-aebd-0000f80367c1")
+	inherit := winacl.ACEHeaderFlags(winacl.ACEHeaderFlagsContainerInheritAce)
+
+	ntsd, err := winacl.NewDescriptor().
+		Deny(everyone, winacl.AccessMaskDelete).
+		Allow(helpdesk, winacl.ADSRightDSReadProp|winacl.ADSRightDSWriteProp, inherit).
+		Allow(helpdesk, winacl.ADSRightDSReadProp|winacl.ADSRightDSWriteProp, inherit).
+		AllowObject(helpdesk, winacl.ADSRightDSWriteProp, personalInfo, winacl.GUID{}).
+		Allow(alice, winacl.AccessMaskDelete).
+		Allow(alice, winacl.ADSRightDSReadProp).
+		Allow(helpdesk, winacl.AccessMaskReadControl).
+		Allow(helpdesk, winacl.AccessMaskGenericRead).
+		Build()
+	r.NoError(err)
+
+	t.Run("Flags duplicate, subsumed and shadowed ACEs", func(t *testing.T) {
+		redundant := ntsd.DACL.RedundantAces()
+		r.Len(redundant, 3)
+
+		r.Equal(2, redundant[0].Index)
+		r.Equal(winacl.RedundancyDuplicate, redundant[0].Kind)
+		r.Equal([]int{1}, redundant[0].CoveredBy)
+
+		r.Equal(3, redundant[1].Index)
+		r.Equal(winacl.RedundancySubsumed, redundant[1].Kind)
+		r.Equal([]int{1, 2}, redundant[1].CoveredBy)
+		r.Equal("rights already granted by ACEs 1 and 2", redundant[1].Reason)
+
+		r.Equal(4, redundant[2].Index)
+		r.Equal(winacl.RedundancyShadowed, redundant[2].Kind)
+		r.Equal("rights already denied by ACE 0", redundant[2].Reason)
+	})
+
+	t.Run("Does not cover broader scopes or other principals", func(t *testing.T) {
+		for _, entry := range ntsd.DACL.RedundantAces() {
+			r.NotEqual(5, entry.Index)
+			r.NotEqual(7, entry.Index)
+		}
+	})
+
+	t.Run("Uses group membership", func(t *testing.T) {
+		membership, err := newTestMembership()
+		r.NoError(err)
+
+		analysis := winacl.RedundancyAnalysis{Membership: membership}
+		redundant := analysis.RedundantAces(*ntsd.DACL)
+		r.Len(redundant, 4)
+		r.Equal(5, redundant[3].Index)
+		r.Equal([]int{1, 2}, redundant[3].CoveredBy)
+
+		simplified, removed, err := analysis.Simplify(*ntsd.DACL)
+		r.NoError(err)
+		r.Len(removed, 4)
+		r.Len(simplified.Aces, 4)
+	})
+
+	t.Run("Simplifies to an equivalent ACL", func(t *testing.T) {
+		simplified, removed, err := ntsd.DACL.Simplify()
+		r.NoError(err)
+		r.Len(removed, 3)
+		r.Len(simplified.Aces, 5)
+		r.Equal(uint16(5), simplified.Header.AceCount)
+		r.Equal(uint8(winacl.ACLRevision), simplified.Header.Revision)
+		r.Len(simplified.RedundantAces(), 0)
+	})
+
+	t.Run("Keeps the last OWNER RIGHTS ACE", func(t *testing.T) {
+		// O:alice D:(A;;RC;;;WD)(A;;RC;;;OW)
+		ownerRights, _ := winacl.ParseSID("S-1-3-4")
+		owned, err := winacl.NewDescriptor().
+			Owner(alice).
+			Allow(everyone, winacl.AccessMaskReadControl).
+			Allow(ownerRights, winacl.AccessMaskReadControl).
+			Build()
+		r.NoError(err)
+
+		r.Empty(owned.DACL.RedundantAces())
+		simplified, removed, err := winacl.RedundancyAnalysis{Owner: owned.Owner}.Simplify(*owned.DACL)
+		r.NoError(err)
+		r.Empty(removed)
+		r.Len(simplified.Aces, 2)
+
+		owned.DACL.Aces = append(owned.DACL.Aces, owned.DACL.Aces[1])
+		redundant := owned.DACL.RedundantAces()
+		r.Len(redundant, 1)
+		r.Equal(2, redundant[0].Index)
+		_, _, err = winacl.RedundancyAnalysis{Owner: owned.Owner}.Simplify(*owned.DACL)
+		r.NoError(err)
+	})
+
+	t.Run("Audits cover narrower outcomes only", func(t *testing.T) {
+		success := winacl.ACEHeaderFlags(winacl.ACEHeaderFlagsSuccessfulAccessAceFlag)
+		failure := winacl.ACEHeaderFlags(winacl.ACEHeaderFlagsFailedAccessAceFlag)
+		audits, err := winacl.NewDescriptor().
+			Audit(everyone, winacl.ADSRightDSWriteProp, success).
+			Audit(alice, winacl.ADSRightDSWriteProp, success, failure).
+			Audit(alice, winacl.ADSRightDSWriteProp, success).
+			Build()
+		r.NoError(err)
+
+		redundant := audits.SACL.RedundantAces()
+		r.Len(redundant, 1)
+		r.Equal(2, redundant[0].Index)
+		r.Equal("rights already audited by ACEs 0 and 1", redundant[0].Reason)
+	})
+}
@@ -0,0 +1,171 @@
+package winacl
+
+import "strings"
+
+// File and service specific access rights, which reuse the low 16 bits
+// that directory service rights occupy on AD objects
+const (
+	FileWriteData  = 0x00000002
+	FileAppendData = 0x00000004
+	FileAllAccess  = 0x001F01FF
+
+	ServiceChangeConfig = 0x00000002
+	ServiceStart        = 0x00000010
+	ServiceStop         = 0x00000020
+	ServicePauseCont    = 0x00000040
+	ServiceAllAccess    = 0x000F01FF
+)
+
+// ADRules returns the built-in rule pack for Active Directory objects
+func ADRules() []Rule {
+	return []Rule{
+		nullDACLRule{RuleID: "ad-null-dacl"},
+		ACERule{
+			RuleID:    "ad-lowpriv-generic-all",
+			Severity:  SeverityCritical,
+			Rationale: "a low privileged group has full control of the object",
+			Match:     lowPrivilegedFullControl(adsRightsGenericAll),
+		},
+		ACERule{
+			RuleID:    "ad-lowpriv-write-dacl",
+			Severity:  SeverityHigh,
+			Rationale: "a low privileged group can modify the object's DACL",
+			Match:     lowPrivilegedGrant(AccessMaskWriteDACL),
+		},
+		ACERule{
+			RuleID:    "ad-lowpriv-write-owner",
+			Severity:  SeverityHigh,
+			Rationale: "a low privileged group can take ownership of the object",
+			Match:     lowPrivilegedGrant(AccessMaskWriteOwner),
+		},
+		ACERule{
+			RuleID:    "ad-lowpriv-generic-write",
+			Severity:  SeverityHigh,
+			Rationale: "a low privileged group can write every property of the object",
+			Match:     lowPrivilegedGrant(AccessMaskGenericWrite | ADSRightDSWriteProp),
+		},
+		ACERule{
+			RuleID:    "ad-lowpriv-all-extended-rights",
+			Severity:  SeverityHigh,
+			Rationale: "a low privileged group holds every extended right on the object",
+			Match:     lowPrivilegedGrant(ADSRightDSControlAccess),
+		},
+		ACERule{
+			RuleID:    "ad-lowpriv-create-child",
+			Severity:  SeverityMedium,
+			Rationale: "a low privileged group can create objects in the container",
+			Match: func(ace ACE, ctx RuleContext) bool {
+				if !strings.EqualFold(ctx.ObjectClass, ObjectClassOrganizationalUnit) &&
+					!strings.EqualFold(ctx.ObjectClass, ObjectClassContainer) {
+					return false
+				}
+				return isAllowACE(ace) &&
+					ace.Header.Flags&ACEHeaderFlagsInheritOnlyAce == 0 &&
+					IsLowPrivilegedSID(ace.ObjectAce.GetPrincipal()) &&
+					ace.AccessMask.Raw()&(ADSRightDSCreateChild|AccessMaskGenericAll) != 0
+			},
+		},
+		DescriptorRule{
+			RuleID:    "ad-privileged-non-admin-owner",
+			Severity:  SeverityHigh,
+			Rationale: "a privileged object is owned by a non-administrative principal",
+			Check: func(ntsd NtSecurityDescriptor, ctx RuleContext) bool {
+				return ctx.Privileged && ntsd.Owner.String() != "" && !IsAdministrativeSID(ntsd.Owner)
+			},
+		},
+	}
+}
+
+// FileShareRules returns the built-in rule pack for file system and
+// share descriptors
+func FileShareRules() []Rule {
+	return []Rule{
+		nullDACLRule{RuleID: "fs-null-dacl"},
+		ACERule{
+			RuleID:    "fs-lowpriv-full-control",
+			Severity:  SeverityCritical,
+			Rationale: "a low privileged group has full control",
+			Match:     lowPrivilegedFullControl(FileAllAccess),
+		},
+		ACERule{
+			RuleID:    "fs-lowpriv-change-permissions",
+			Severity:  SeverityHigh,
+			Rationale: "a low privileged group can change permissions or take ownership",
+			Match:     lowPrivilegedGrant(AccessMaskWriteDACL | AccessMaskWriteOwner),
+		},
+		ACERule{
+			RuleID:    "fs-lowpriv-modify",
+			Severity:  SeverityHigh,
+			Rationale: "a low privileged group can modify or delete data",
+			Match: lowPrivilegedGrant(AccessMaskGenericWrite | AccessMaskDelete |
+				FileWriteData | FileAppendData),
+		},
+		ACERule{
+			RuleID:    "fs-anonymous-access",
+			Severity:  SeverityMedium,
+			Rationale: "anonymous logons are granted access",
+			Match: func(ace ACE, ctx RuleContext) bool {
+				return isAllowACE(ace) && ace.ObjectAce.GetPrincipal().String() == "S-1-5-7"
+			},
+		},
+	}
+}
+
+// ServiceRules returns the built-in rule pack for Windows service
+// descriptors
+func ServiceRules() []Rule {
+	return []Rule{
+		nullDACLRule{RuleID: "svc-null-dacl"},
+		ACERule{
+			RuleID:    "svc-lowpriv-all-access",
+			Severity:  SeverityCritical,
+			Rationale: "a low privileged group has full control of the service",
+			Match:     lowPrivilegedFullControl(ServiceAllAccess),
+		},
+		ACERule{
+			RuleID:    "svc-lowpriv-change-config",
+			Severity:  SeverityCritical,
+			Rationale: "a low privileged group can reconfigure the service binary or account",
+			Match:     lowPrivilegedGrant(AccessMaskGenericWrite | ServiceChangeConfig),
+		},
+		ACERule{
+			RuleID:    "svc-lowpriv-change-permissions",
+			Severity:  SeverityCritical,
+			Rationale: "a low privileged group can change the service's permissions or owner",
+			Match:     lowPrivilegedGrant(AccessMaskWriteDACL | AccessMaskWriteOwner),
+		},
+		ACERule{
+			RuleID:    "svc-lowpriv-start-stop",
+			Severity:  SeverityLow,
+			Rationale: "a low privileged group can start or stop the service",
+			Match:     lowPrivilegedGrant(ServiceStart | ServiceStop | ServicePauseCont),
+		},
+	}
+}
+
+// nullDACLRule reports descriptors whose DACL is NULL, which grants
+// Everyone full control
+type nullDACLRule struct {
+	RuleID string
+}
+
+// ID returns the rule's identifier
+func (r nullDACLRule) ID() string {
+	return r.RuleID
+}
+
+// Evaluate reports the descriptor if its DACL is NULL
+func (r nullDACLRule) Evaluate(ntsd NtSecurityDescriptor, ctx RuleContext) []Finding {
+	if ntsd.DACL != nil {
+		return nil
+	}
+
+	everyone, _ := ParseSID(everyoneSID)
+	return []Finding{{
+		RuleID:    r.RuleID,
+		Severity:  SeverityCritical,
+		Rationale: "the descriptor has a NULL DACL, which grants everyone full control",
+		Principal: everyone,
+		AceIndex:  -1,
+	}}
+}
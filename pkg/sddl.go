@@ -65,23 +65,61 @@ var AceRightsSDDL = map[uint32]string{
 	ADSRightDSControlAccess: "CR",
 }
 
+// AceRightsAliasSDDL maps whole file and registry key access masks to
+// the SDDL aliases standing for them. Unlike AceRightsSDDL, an alias is
+// only used when it matches an ACE's mask exactly
+var AceRightsAliasSDDL = map[uint32]string{
+	FileAllAccess: "FA",
+	0x00120089:    "FR",
+	0x00120116:    "FW",
+	0x001200A0:    "FX",
+	0x000F003F:    "KA",
+	0x00020019:    "KR",
+	0x00020006:    "KW",
+}
+
 // https://docs.microsoft.com/en-us/windows/win32/secauthz/security-descriptor-control
+//
+// Deprecated: use DACLAutoInheritReq, DACLAutoInherited and DACLProtected
 const (
 	ControlDACLAutoInheritReq = 0x100
 	ControlDACLAutoInherit    = 0x400
 	ControlDACLProtected      = 0x1000
 )
 
-// NtSecurityDescriptorHeaderSDDL holds the Security Descriptor
-// Control property mapped to its corresponding SDDL abbreviations
-// NOTE: ntsd.ToSDDL() relies on these being the only 3 entries
-// in this map.
-var NtSecurityDescriptorHeaderSDDL = map[int]string{
-	ControlDACLAutoInheritReq: "AR",
-	ControlDACLAutoInherit:    "AI",
-	ControlDACLProtected:      "P",
+// DACLControlSDDL holds the Control flags written after "D:" mapped
+// to their corresponding SDDL abbreviations
+var DACLControlSDDL = map[ControlFlags]string{
+	DACLProtected:      "P",
+	DACLAutoInheritReq: "AR",
+	DACLAutoInherited:  "AI",
+}
+
+// SACLControlSDDL holds the Control flags written after "S:" mapped
+// to their corresponding SDDL abbreviations
+var SACLControlSDDL = map[ControlFlags]string{
+	SACLProtected:      "P",
+	SACLAutoInheritReq: "AR",
+	SACLAutoInherited:  "AI",
 }
 
+// NtSecurityDescriptorHeaderSDDL holds the DACL's Control flags mapped
+// to their corresponding SDDL abbreviations
+//
+// Deprecated: use DACLControlSDDL
+var NtSecurityDescriptorHeaderSDDL = newNtSecurityDescriptorHeaderSDDL()
+
+func newNtSecurityDescriptorHeaderSDDL() map[int]string {
+	symbols := make(map[int]string)
+	for flag, symbol := range DACLControlSDDL {
+		symbols[int(flag)] = symbol
+	}
+	return symbols
+}
+
+// sddlNoAccessControl stands for a NULL ACL
+const sddlNoAccessControl = "NO_ACCESS_CONTROL"
+
 // WellKnownSIDsSSDL is a map of common Windows SIDs mapped to
 // their corresponding abbreviations
 var WellKnownSIDsSSDL = map[string]string{
@@ -149,13 +187,22 @@ var WellKnownSIDsSSDL = map[string]string{
 }
 
 // RightsString returns the representation of an ACE's permissions,
-// in SDDL format
+// in SDDL format. Masks matching an AceRightsAliasSDDL entry are written
+// as that alias, others one AceRightsSDDL symbol per bit, or as a hex
+// mask such as "0x1200a9" when a bit has no symbol
 func (s ACE) RightsString() string {
-	sb := strings.Builder{}
-	flags, _ := bamflags.ParseInt(int64(s.AccessMask.value))
+	mask := s.AccessMask.value
+	if alias, found := AceRightsAliasSDDL[mask]; found {
+		return alias
+	}
 
+	sb := strings.Builder{}
+	flags, _ := bamflags.ParseInt(int64(mask))
 	for _, flag := range flags {
-		symbol := AceRightsSDDL[uint32(flag)]
+		symbol, found := AceRightsSDDL[uint32(flag)]
+		if !found {
+			return fmt.Sprintf("0x%x", mask)
+		}
 		sb.WriteString(symbol)
 	}
 	return sb.String()
@@ -209,12 +256,15 @@ func (s ACE) ToSDDL() string {
 	return sddlString
 }
 
-// ToSDDL will convert the individual components of an ACD
+// ToSDDL will convert the individual components of a DACL
 // into an SDDL compliant string
 func (a ACL) ToSDDL(flags string) string {
+	return a.toSDDL("D:", flags)
+}
+
+func (a ACL) toSDDL(prefix string, flags string) string {
 	sb := strings.Builder{}
-	// TODO Change when SACLs are implemented
-	sb.WriteString("D:")
+	sb.WriteString(prefix)
 	sb.WriteString(flags)
 	for _, ace := range a.Aces {
 		sb.WriteString(ace.ToSDDL())
@@ -222,31 +272,57 @@ func (a ACL) ToSDDL(flags string) string {
 	return sb.String()
 }
 
-// ToSDDL will convert Control value of an NtSecurityDescriptorHeader
-// into an SDDL compliant string
+// ToSDDL will convert the DACL flags of an NtSecurityDescriptorHeader's
+// Control into an SDDL compliant string
 func (ndh NtSecurityDescriptorHeader) ToSDDL() string {
-	// ControlDACLAutoInheritReq = 0x100 = AR
-	// ControlDACLAutoInherit    = 0x400 = AI
-	// ControlDACLProtected      = 0x1000 = P
-	sb := strings.Builder{}
-	flags, _ := bamflags.ParseInt(int64(ndh.Control))
+	return ndh.Control.DACLSDDL()
+}
 
-	for _, flag := range flags {
-		symbol := NtSecurityDescriptorHeaderSDDL[flag]
-		sb.WriteString(symbol)
-	}
+// DACLSDDL returns the SDDL flags written after "D:", in the order
+// Windows writes them: P, AR, AI
+func (f ControlFlags) DACLSDDL() string {
+	return f.sddlFlags(DACLControlSDDL, DACLProtected, DACLAutoInheritReq, DACLAutoInherited)
+}
+
+// SACLSDDL returns the SDDL flags written after "S:", in the order
+// Windows writes them: P, AR, AI
+func (f ControlFlags) SACLSDDL() string {
+	return f.sddlFlags(SACLControlSDDL, SACLProtected, SACLAutoInheritReq, SACLAutoInherited)
+}
 
+func (f ControlFlags) sddlFlags(lookup map[ControlFlags]string, order ...ControlFlags) string {
+	sb := strings.Builder{}
+	for _, flag := range order {
+		if f&flag != 0 {
+			sb.WriteString(lookup[flag])
+		}
+	}
 	return sb.String()
 }
 
 // ToSDDL will convert the individual components of a NtSecurityDescriptor
-// into an SDDL compliant string
+// into an SDDL compliant string. A missing DACL is written as
+// D:NO_ACCESS_CONTROL; the SACL is only written when SACLPresent is set
 //
 // https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/2918391b-75b9-4eeb-83f0-7fdc04a5c6c9
 func (s NtSecurityDescriptor) ToSDDL() string {
 	sb := strings.Builder{}
 	fmt.Fprintf(&sb, "O:%s", s.Owner.String())
 	fmt.Fprintf(&sb, "G:%s", s.Group.String())
-	sb.WriteString(s.DACL.ToSDDL(s.Header.ToSDDL()))
+
+	daclFlags := s.Header.Control.DACLSDDL()
+	if s.DACL != nil {
+		sb.WriteString(s.DACL.toSDDL("D:", daclFlags))
+	} else {
+		sb.WriteString("D:" + daclFlags + sddlNoAccessControl)
+	}
+
+	saclFlags := s.Header.Control.SACLSDDL()
+	switch {
+	case s.SACL.isPresent():
+		sb.WriteString(s.SACL.toSDDL("S:", saclFlags))
+	case s.Header.Control&SACLPresent != 0:
+		sb.WriteString("S:" + saclFlags + sddlNoAccessControl)
+	}
 	return sb.String()
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -146,6 +147,71 @@ func NewSID(buf *bytes.Buffer, sidLength int) (SID, error) {
 	}
 }
 
+// ParseSID is a constructor that will parse out a SID from its
+// S-R-I-S-S... string representation
+func ParseSID(s string) (SID, error) {
+	sid := SID{}
+	parts := strings.Split(s, "-")
+
+	if len(parts) < 3 || !strings.EqualFold(parts[0], "S") {
+		return sid, SIDInvalidError{fmt.Sprintf("invalid SID string %q", s)}
+	}
+	if numAuth := len(parts) - 3; numAuth > 15 {
+		return sid, SIDInvalidError{"invalid number of subauthorities"}
+	}
+
+	revision, err := strconv.ParseUint(parts[1], 10, 8)
+	if err != nil || revision != 1 {
+		return sid, SIDInvalidError{"invalid SID revision"}
+	}
+
+	authority, err := strconv.ParseUint(parts[2], 0, 48)
+	if err != nil {
+		return sid, SIDInvalidError{fmt.Sprintf("invalid SID authority %q", parts[2])}
+	}
+
+	subAuth := make([]uint32, 0, len(parts)-3)
+	for _, part := range parts[3:] {
+		value, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return sid, SIDInvalidError{fmt.Sprintf("invalid SID subauthority %q", part)}
+		}
+		subAuth = append(subAuth, uint32(value))
+	}
+
+	sid.Revision = byte(revision)
+	sid.NumAuthorities = byte(len(subAuth))
+	sid.Authority = make([]byte, 6)
+	for i := 0; i < 6; i++ {
+		sid.Authority[5-i] = byte(authority >> (8 * i))
+	}
+	sid.SubAuthorities = subAuth
+
+	return sid, nil
+}
+
+// Size returns the length of a SID's binary representation
+func (s SID) Size() int {
+	return 8 + 4*len(s.SubAuthorities)
+}
+
+// ToBuffer serializes a SID to its binary representation
+func (s SID) ToBuffer() (bytes.Buffer, error) {
+	buf := bytes.Buffer{}
+	if len(s.Authority) != 6 {
+		return buf, SIDInvalidError{"invalid SID authority"}
+	}
+	if len(s.SubAuthorities) > 15 {
+		return buf, SIDInvalidError{"invalid number of subauthorities"}
+	}
+
+	buf.WriteByte(s.Revision)
+	buf.WriteByte(byte(len(s.SubAuthorities)))
+	buf.Write(s.Authority)
+	err := binary.Write(&buf, binary.LittleEndian, s.SubAuthorities)
+	return buf, err
+}
+
 // Resolve will return the human readable description of a SID
 // If one does not exist, it will return in the normal "S-!-" notation
 func (s SID) Resolve() string {
@@ -21,3 +21,24 @@ func TestNewSID(t *testing.T) {
 	})
 
 }
+
+func TestParseSID(t *testing.T) {
+
+	r := require.New(t)
+
+	t.Run("Parses the string form of a SID", func(t *testing.T) {
+		sidStr := "S-1-5-21-2333832797-2102143736-1942374753-512"
+		sid, err := winacl.ParseSID(sidStr)
+		r.NoError(err)
+		r.Equal(sidStr, sid.String())
+		r.Equal(newTestSD().Owner, sid)
+	})
+
+	t.Run("Returns an error when given a malformed SID string", func(t *testing.T) {
+		for _, sidStr := range []string{"", "S-1", "S-2-5-32", "S-1-5-x", "X-1-5-32"} {
+			_, err := winacl.ParseSID(sidStr)
+			r.IsType(winacl.SIDInvalidError{}, err, sidStr)
+		}
+	})
+
+}
@@ -1,7 +1,9 @@
 package winacl_test
 
 import (
+	"bytes"
 	"encoding/base64"
+	"encoding/binary"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -33,3 +35,62 @@ func newTestSD() winacl.NtSecurityDescriptor {
 	ntsd, _ := winacl.NewNtSecurityDescriptor(ntsdBytes)
 	return ntsd
 }
+
+func newTestGUIDResolver() (*winacl.GUIDResolver, error) {
+	schema, err := os.Open(filepath.Join(getTestDataDir(), "schema.ldif"))
+	if err != nil {
+		return nil, err
+	}
+	defer schema.Close()
+
+	resolver := winacl.NewGUIDResolver()
+	err = resolver.LoadSchemaLDIF(schema)
+	return resolver, err
+}
+
+// newTestObjectACE builds an ACCESS_ALLOWED_OBJECT ACE for Everyone
+// carrying the given mask and ObjectType
+func newTestObjectACE(mask uint32, objectType winacl.GUID) (winacl.ACE, error) {
+	everyone := []byte{1, 1, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0}
+	header := winacl.ACEHeader{
+		Type: winacl.AceTypeAccessAllowedObject,
+		Size: uint16(4 + 4 + 4 + 16 + len(everyone)),
+	}
+
+	buf := bytes.Buffer{}
+	binary.Write(&buf, binary.LittleEndian, header)
+	binary.Write(&buf, binary.LittleEndian, mask)
+	binary.Write(&buf, binary.LittleEndian, uint32(winacl.ACEInheritanceFlagsObjectTypePresent))
+	binary.Write(&buf, binary.LittleEndian, objectType)
+	buf.Write(everyone)
+
+	return winacl.NewAce(&buf)
+}
+
+// newTestBasicACE builds an ACE of the given basic type granting the
+// mask to the principal
+func newTestBasicACE(aceType winacl.AceType, flags winacl.ACEHeaderFlags, mask uint32, principal string) (winacl.ACE, error) {
+	sid, err := winacl.ParseSID(principal)
+	if err != nil {
+		return winacl.ACE{}, err
+	}
+
+	sidBuf := bytes.Buffer{}
+	sidBuf.WriteByte(sid.Revision)
+	sidBuf.WriteByte(sid.NumAuthorities)
+	sidBuf.Write(sid.Authority)
+	binary.Write(&sidBuf, binary.LittleEndian, sid.SubAuthorities)
+
+	header := winacl.ACEHeader{
+		Type:  aceType,
+		Flags: flags,
+		Size:  uint16(4 + 4 + sidBuf.Len()),
+	}
+
+	buf := bytes.Buffer{}
+	binary.Write(&buf, binary.LittleEndian, header)
+	binary.Write(&buf, binary.LittleEndian, mask)
+	buf.Write(sidBuf.Bytes())
+
+	return winacl.NewAce(&buf)
+}